@@ -0,0 +1,407 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Entry is a phrase in a batch. Any settings it leaves out are taken
+// from the flags.
+type Entry struct {
+	Text string `json:"text"`
+	// Name is the name of the output file, without the extension. If
+	// it is empty, the name is made from the entry's position and
+	// text.
+	Name    string `json:"name"`
+	Format  string `json:"format"`
+	Font    string `json:"font"`
+	Style   string `json:"style"`
+	Palette string `json:"palette"`
+	// Foreground and Background replace the colours of the palette,
+	// given as #rrggbb
+	Foreground string   `json:"foreground"`
+	Background string   `json:"background"`
+	Width      float64  `json:"width"`
+	Height     float64  `json:"height"`
+	Padding    *float64 `json:"padding"`
+}
+
+// manifestFormats are the formats a batch can be given in
+var manifestFormats = []string{"text", "json", "csv"}
+
+// readManifest reads the entries of a batch. Text manifests have a
+// phrase on each line, JSON manifests are an array of entries, and CSV
+// manifests have a header row naming the fields of the entry in each
+// column.
+func readManifest(r io.Reader, format string) ([]Entry, error) {
+	switch format {
+	case "text":
+		entries := []Entry{}
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				entries = append(entries, Entry{Text: line})
+			}
+		}
+		return entries, scanner.Err()
+	case "json":
+		entries := []Entry{}
+		decoder := json.NewDecoder(r)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&entries); err != nil {
+			return nil, err
+		}
+		return entries, nil
+	case "csv":
+		return readCSVManifest(r)
+	}
+	return nil, fmt.Errorf("unknown manifest format '%s', expected one of %s", format, strings.Join(manifestFormats, ", "))
+}
+
+// readCSVManifest reads the entries of a CSV manifest. Empty cells are
+// left for the flags to fill in.
+func readCSVManifest(r io.Reader) ([]Entry, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("manifest has no header row")
+	}
+	header := rows[0]
+	entries := make([]Entry, 0, len(rows)-1)
+	for i, row := range rows[1:] {
+		entry := Entry{}
+		for column, value := range row {
+			if value == "" {
+				continue
+			}
+			if err := entry.set(strings.ToLower(strings.TrimSpace(header[column])), value); err != nil {
+				return nil, fmt.Errorf("row %d: %w", i+2, err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// set sets the field of the entry named by a CSV column
+func (entry *Entry) set(field, value string) error {
+	number := func() (float64, error) {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%s '%s' is not a number", field, value)
+		}
+		return v, nil
+	}
+	var err error
+	switch field {
+	case "text":
+		entry.Text = value
+	case "name":
+		entry.Name = value
+	case "format":
+		entry.Format = value
+	case "font":
+		entry.Font = value
+	case "style":
+		entry.Style = value
+	case "palette":
+		entry.Palette = value
+	case "foreground":
+		entry.Foreground = value
+	case "background":
+		entry.Background = value
+	case "width":
+		entry.Width, err = number()
+	case "height":
+		entry.Height, err = number()
+	case "padding":
+		var padding float64
+		padding, err = number()
+		entry.Padding = &padding
+	default:
+		err = fmt.Errorf("unknown column '%s'", field)
+	}
+	return err
+}
+
+// configure returns the configuration with the entry's settings in
+// place of the flags
+func (entry *Entry) configure(config Configuration) Configuration {
+	if entry.Format != "" {
+		config.Format = entry.Format
+	}
+	if entry.Font != "" {
+		config.Font = entry.Font
+	}
+	if entry.Style != "" {
+		config.Style = entry.Style
+	}
+	if entry.Palette != "" {
+		config.Palette = entry.Palette
+	}
+	if entry.Foreground != "" {
+		config.Foreground = entry.Foreground
+	}
+	if entry.Background != "" {
+		config.Background = entry.Background
+	}
+	if entry.Width > 0 {
+		config.Width = entry.Width
+	}
+	if entry.Height > 0 {
+		config.Height = entry.Height
+	}
+	if entry.Padding != nil {
+		config.Padding = *entry.Padding
+	}
+	return config
+}
+
+// slug turns text into something safe to use in a file name. Accents
+// are taken off letters, and anything else that isn't a letter or
+// digit becomes a dash.
+func slug(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, char := range norm.NFD.String(strings.ToLower(text)) {
+		if unicode.Is(unicode.Mn, char) {
+			continue
+		}
+		if char >= 'a' && char <= 'z' || char >= '0' && char <= '9' {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(char)
+			dash = false
+		} else {
+			dash = true
+		}
+		if b.Len() >= 40 {
+			break
+		}
+	}
+	if b.Len() == 0 {
+		return "phrase"
+	}
+	return b.String()
+}
+
+// fileNames returns the name of the output file for each entry. Names
+// made from the text are numbered in the order of the manifest, so
+// the same manifest always gives the same names.
+func fileNames(entries []Entry, formats []string) ([]string, error) {
+	digits := len(strconv.Itoa(len(entries)))
+	if digits < 3 {
+		digits = 3
+	}
+	names := make([]string, len(entries))
+	used := map[string]int{}
+	for i, entry := range entries {
+		name := entry.Name
+		if strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+			return nil, fmt.Errorf("entry %d has name '%s', which isn't a plain file name", i+1, name)
+		}
+		if name == "" {
+			name = fmt.Sprintf("%0*d-%s", digits, i+1, slug(entry.Text))
+		}
+		name += "." + formats[i]
+		if other, ok := used[name]; ok {
+			return nil, fmt.Errorf("entries %d and %d are both named '%s'", other+1, i+1, name)
+		}
+		used[name] = i
+		names[i] = name
+	}
+	return names, nil
+}
+
+// zipModified is the modification time given to files in a batch's
+// zip, so that the same manifest always gives the same zip. It is the
+// earliest time a zip can hold.
+var zipModified = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// batch renders every entry in a manifest. The files are written into
+// a zip file if the output ends in .zip, or to stdout as a zip if it
+// is "-", and otherwise into the output directory. If any entry
+// fails, the files already written are removed.
+func (config *Configuration) batch(manifest io.Reader, format, output string) (err error) {
+	entries, err := readManifest(manifest, format)
+	if err != nil {
+		return fmt.Errorf("could not read manifest: %w", err)
+	}
+	configs := make([]Configuration, len(entries))
+	formats := make([]string, len(entries))
+	for i := range entries {
+		configs[i] = entries[i].configure(*config)
+		if configs[i].Format == "" {
+			configs[i].Format = "png"
+		}
+		if formats[i], err = configs[i].format(""); err != nil {
+			return fmt.Errorf("entry %d: %w", i+1, err)
+		}
+	}
+	names, err := fileNames(entries, formats)
+	if err != nil {
+		return err
+	}
+
+	// each file is written under a temporary name, and only renamed
+	// once the whole batch has been written, so that a failed batch
+	// leaves any files it would have replaced as they were
+	pending := []pendingFile{}
+	// createdDir is the output directory if the batch made it, to
+	// remove if it fails
+	createdDir := ""
+	var archiveFile *os.File
+	defer func() {
+		if err == nil {
+			return
+		}
+		if archiveFile != nil {
+			archiveFile.Close()
+		}
+		for _, file := range pending {
+			os.Remove(file.temp)
+		}
+		if createdDir != "" {
+			os.Remove(createdDir)
+		}
+	}()
+	createTemp := func(path string) (*os.File, error) {
+		f, err := tempFile(path)
+		if err == nil {
+			pending = append(pending, pendingFile{temp: f.Name(), path: path})
+		}
+		return f, err
+	}
+
+	// create is called to get where to write each file
+	var create func(name string) (io.WriteCloser, error)
+	var archive *zip.Writer
+	switch {
+	case output == "-" || strings.EqualFold(filepath.Ext(output), ".zip"):
+		out := io.Writer(os.Stdout)
+		if output != "-" {
+			f, err := createTemp(output)
+			if err != nil {
+				return err
+			}
+			archiveFile = f
+			out = f
+		}
+		archive = zip.NewWriter(out)
+		create = func(name string) (io.WriteCloser, error) {
+			w, err := archive.CreateHeader(&zip.FileHeader{
+				Name:     name,
+				Method:   zip.Deflate,
+				Modified: zipModified,
+			})
+			return nopCloser{w}, err
+		}
+	default:
+		if _, err := os.Stat(output); os.IsNotExist(err) {
+			createdDir = output
+		}
+		if err := os.MkdirAll(output, 0755); err != nil {
+			return err
+		}
+		create = func(name string) (io.WriteCloser, error) {
+			return createTemp(filepath.Join(output, name))
+		}
+	}
+
+	for i, entry := range entries {
+		if strings.TrimSpace(entry.Text) == "" {
+			return fmt.Errorf("entry %d has no text", i+1)
+		}
+		keyframes, err := configs[i].keyframes(entry.Text)
+		if err != nil {
+			return fmt.Errorf("entry %d: %w", i+1, err)
+		}
+		// render in full before creating the file, so that a failed
+		// entry doesn't leave part of a file behind
+		var buf bytes.Buffer
+		if err := configs[i].write(&buf, formats[i], keyframes); err != nil {
+			return fmt.Errorf("entry %d: %w", i+1, err)
+		}
+		w, err := create(names[i])
+		if err != nil {
+			return err
+		}
+		if _, err := buf.WriteTo(w); err != nil {
+			w.Close()
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+	}
+	if archive != nil {
+		if err := archive.Close(); err != nil {
+			return err
+		}
+	}
+	if archiveFile != nil {
+		// the file is closed here rather than by the cleanup, as an
+		// error closing it means the zip is incomplete
+		f := archiveFile
+		archiveFile = nil
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	for _, file := range pending {
+		if err := os.Rename(file.temp, file.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pendingFile is a file written under a temporary name, to be renamed
+// to its path once everything has been written
+type pendingFile struct {
+	temp string
+	path string
+}
+
+// tempFile creates a file to be renamed to path once it has been
+// written. It is made next to path, so that renaming it doesn't move
+// it between filesystems.
+func tempFile(path string) (*os.File, error) {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return nil, err
+	}
+	// temporary files are only readable by their owner, but the
+	// output is for sharing
+	if err := f.Chmod(0644); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return f, nil
+}
+
+// nopCloser adds a Close method that does nothing to a writer
+type nopCloser struct {
+	io.Writer
+}
+
+// Close does nothing
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"archive/zip"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/joshbarrass/SnakeIsDead/pkg/letters"
+	"github.com/joshbarrass/SnakeIsDead/pkg/render"
+)
+
+func TestReadManifest(t *testing.T) {
+	padding := 5.0
+	tests := []struct {
+		format   string
+		manifest string
+		want     []Entry
+	}{
+		{"text", "SNAKE IS DEAD\n\n  PARADOX  \n", []Entry{{Text: "SNAKE IS DEAD"}, {Text: "PARADOX"}}},
+		{"text", "", []Entry{}},
+		{
+			"json",
+			`[{"text": "SNAKE", "name": "snake", "format": "gif", "palette": "paradox", "foreground": "#ff0000", "background": "#000", "width": 200, "padding": 5}, {"text": "DEAD"}]`,
+			[]Entry{
+				{Text: "SNAKE", Name: "snake", Format: "gif", Palette: "paradox", Foreground: "#ff0000", Background: "#000", Width: 200, Padding: &padding},
+				{Text: "DEAD"},
+			},
+		},
+		{
+			"csv",
+			"Text, Font ,style,foreground,background,height,padding\nSNAKE,heavy,black,#fff,#123456,100,5\nDEAD,,,,,,\n",
+			[]Entry{
+				{Text: "SNAKE", Font: "heavy", Style: "black", Foreground: "#fff", Background: "#123456", Height: 100, Padding: &padding},
+				{Text: "DEAD"},
+			},
+		},
+	}
+	for _, test := range tests {
+		entries, err := readManifest(strings.NewReader(test.manifest), test.format)
+		if err != nil {
+			t.Errorf("%s manifest %q: readManifest returned error: %s", test.format, test.manifest, err)
+			continue
+		}
+		if !reflect.DeepEqual(entries, test.want) {
+			t.Errorf("%s manifest %q: readManifest returned %+v, want %+v", test.format, test.manifest, entries, test.want)
+		}
+	}
+}
+
+func TestReadManifestErrors(t *testing.T) {
+	tests := []struct {
+		format   string
+		manifest string
+		want     string
+	}{
+		{"yaml", "- text: SNAKE", "unknown manifest format 'yaml'"},
+		{"json", `{"text": "SNAKE"}`, "cannot unmarshal object"},
+		{"json", `[{"text": "SNAKE", "colour": "red"}]`, `unknown field "colour"`},
+		{"json", `[{"text": "SNAKE", "width": "wide"}]`, "cannot unmarshal string"},
+		{"csv", "", "no header row"},
+		{"csv", "text,colour\nSNAKE,red\n", "row 2: unknown column 'colour'"},
+		{"csv", "text,width\nSNAKE,100\nDEAD,wide\n", "row 3: width 'wide' is not a number"},
+		{"csv", "text,padding\nSNAKE,lots\n", "row 2: padding 'lots' is not a number"},
+		{"csv", "text,width\nSNAKE\n", "wrong number of fields"},
+		{"csv", "text\n\"SNAKE\n", "extraneous or missing \" in quoted-field"},
+	}
+	for _, test := range tests {
+		_, err := readManifest(strings.NewReader(test.manifest), test.format)
+		if err == nil {
+			t.Errorf("%s manifest %q: readManifest returned no error, want %q", test.format, test.manifest, test.want)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s manifest %q: readManifest returned error %q, want %q", test.format, test.manifest, err, test.want)
+		}
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"SNAKE IS DEAD", "snake-is-dead"},
+		{"  Snake -- is, dead!  ", "snake-is-dead"},
+		{"ÅNGSTRÖM", "angstrom"},
+		{"12:30", "12-30"},
+		{"!?", "phrase"},
+		{"", "phrase"},
+		{strings.Repeat("AB ", 20), strings.TrimSuffix(strings.Repeat("ab-", 13), "-") + "-a"},
+	}
+	for _, test := range tests {
+		if got := slug(test.text); got != test.want {
+			t.Errorf("slug(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestFileNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []Entry
+		formats []string
+		want    []string
+		err     string
+	}{
+		{
+			name:    "numbered",
+			entries: []Entry{{Text: "SNAKE"}, {Text: "SNAKE"}, {Text: "DEAD", Name: "dead"}},
+			formats: []string{"png", "png", "gif"},
+			want:    []string{"001-snake.png", "002-snake.png", "dead.gif"},
+		},
+		{
+			// the same name in different formats doesn't collide
+			name:    "formats",
+			entries: []Entry{{Text: "SNAKE", Name: "snake"}, {Text: "SNAKE", Name: "snake"}},
+			formats: []string{"png", "svg"},
+			want:    []string{"snake.png", "snake.svg"},
+		},
+		{
+			name:    "same name",
+			entries: []Entry{{Text: "SNAKE", Name: "snake"}, {Text: "DEAD"}, {Text: "IS", Name: "snake"}},
+			formats: []string{"png", "png", "png"},
+			err:     "entries 1 and 3 are both named 'snake.png'",
+		},
+		{
+			// a name can collide with one made from the text
+			name:    "made name",
+			entries: []Entry{{Text: "SNAKE"}, {Text: "DEAD", Name: "001-snake"}},
+			formats: []string{"png", "png"},
+			err:     "entries 1 and 2 are both named '001-snake.png'",
+		},
+		{
+			name:    "path",
+			entries: []Entry{{Text: "SNAKE", Name: "../snake"}},
+			formats: []string{"png"},
+			err:     "isn't a plain file name",
+		},
+		{
+			name:    "parent",
+			entries: []Entry{{Text: "SNAKE", Name: ".."}},
+			formats: []string{"png"},
+			err:     "isn't a plain file name",
+		},
+	}
+	for _, test := range tests {
+		names, err := fileNames(test.entries, test.formats)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: fileNames returned error %v, want %q", test.name, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: fileNames returned error: %s", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(names, test.want) {
+			t.Errorf("%s: fileNames returned %v, want %v", test.name, names, test.want)
+		}
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		value string
+		want  color.RGBA
+		err   bool
+	}{
+		{"#ff8000", color.RGBA{0xff, 0x80, 0x00, 0xff}, false},
+		{"FF8000", color.RGBA{0xff, 0x80, 0x00, 0xff}, false},
+		{"#f80", color.RGBA{0xff, 0x88, 0x00, 0xff}, false},
+		{"#ff80", color.RGBA{}, true},
+		{"#gg8000", color.RGBA{}, true},
+		{"red", color.RGBA{}, true},
+		{"", color.RGBA{}, true},
+	}
+	for _, test := range tests {
+		got, err := parseColor(test.value)
+		if (err != nil) != test.err {
+			t.Errorf("parseColor(%q) returned error %v, want error %v", test.value, err, test.err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("parseColor(%q) = %v, want %v", test.value, got, test.want)
+		}
+	}
+}
+
+// tempDir creates a directory for a test, and returns it along with
+// a function removing it
+func tempDir(t *testing.T) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "snakerender")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+// testConfig returns the configuration given by the default flags
+func testConfig() Configuration {
+	return Configuration{
+		Font:     letters.DefaultFont.Name,
+		Palette:  letters.ModeDeath.String(),
+		Fallback: letters.FallbackError.String(),
+		Padding:  20,
+		FPS:      render.DefaultFPS,
+		Page:     "a4",
+		Units:    "mm",
+	}
+}
+
+func TestBatchZip(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	output := filepath.Join(dir, "out.zip")
+	config := testConfig()
+	config.Width = 100
+	manifest := `[{"text": "SNAKE"}, {"text": "DEAD", "name": "dead", "foreground": "#ff0000", "background": "#0000ff"}]`
+	if err := config.batch(strings.NewReader(manifest), "json", output); err != nil {
+		t.Fatalf("batch returned error: %s", err)
+	}
+
+	archive, err := zip.OpenReader(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+	names := []string{}
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+		if !f.Modified.Equal(zipModified) {
+			t.Errorf("%s was modified at %v, want %v", f.Name, f.Modified, zipModified)
+		}
+	}
+	if want := []string{"001-snake.png", "dead.png"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("zip holds %v, want %v", names, want)
+	}
+
+	// the second entry is drawn in its own colours
+	r, err := archive.File[1].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	img, err := png.Decode(r)
+	if err != nil {
+		t.Fatalf("could not decode %s: %s", names[1], err)
+	}
+	if got := img.Bounds().Dx(); got != 100 {
+		t.Errorf("%s is %d wide, want 100", names[1], got)
+	}
+	if got, want := color.RGBAModel.Convert(img.At(0, 0)), (color.RGBA{0, 0, 0xff, 0xff}); got != want {
+		t.Errorf("%s has background %v, want %v", names[1], got, want)
+	}
+}
+
+func TestBatchFailureRemovesOutput(t *testing.T) {
+	// the second entry can't be drawn, after the first has been
+	// written
+	tests := []struct {
+		name   string
+		output string
+	}{
+		{"zip", "out.zip"},
+		{"new directory", "out"},
+	}
+	dir, remove := tempDir(t)
+	defer remove()
+	for _, test := range tests {
+		output := filepath.Join(dir, test.output)
+		config := testConfig()
+		err := config.batch(strings.NewReader("SNAKE\n☃\n"), "text", output)
+		if err == nil || !strings.Contains(err.Error(), "entry 2") {
+			t.Errorf("%s: batch returned error %v, want one for entry 2", test.name, err)
+		}
+		if _, err := os.Stat(output); !os.IsNotExist(err) {
+			t.Errorf("%s: %s was left behind", test.name, test.output)
+		}
+	}
+
+	// a zip that already existed is left as it was
+	existingZip := filepath.Join(dir, "existing.zip")
+	if err := ioutil.WriteFile(existingZip, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := testConfig()
+	if err := config.batch(strings.NewReader("SNAKE\n☃\n"), "text", existingZip); err == nil {
+		t.Errorf("existing zip: batch returned no error")
+	}
+	if contents, err := ioutil.ReadFile(existingZip); err != nil || string(contents) != "old" {
+		t.Errorf("existing zip holds %q after failing, want it left as it was", contents)
+	}
+
+	// a directory that already existed is kept, along with anything
+	// already in it, including files the batch would have replaced
+	existingDir := filepath.Join(dir, "existing")
+	if err := os.Mkdir(existingDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	existing := map[string]string{"existing.txt": "", "001-snake.png": "old"}
+	for name, contents := range existing {
+		if err := ioutil.WriteFile(filepath.Join(existingDir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := config.batch(strings.NewReader("SNAKE\n☃\n"), "text", existingDir); err == nil {
+		t.Errorf("existing directory: batch returned no error")
+	}
+	files, err := ioutil.ReadDir(existingDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(existing) {
+		t.Errorf("existing directory holds %d files after failing, want %d", len(files), len(existing))
+	}
+	for name, want := range existing {
+		if contents, err := ioutil.ReadFile(filepath.Join(existingDir, name)); err != nil || string(contents) != want {
+			t.Errorf("existing directory: %s holds %q after failing, want %q", name, contents, want)
+		}
+	}
+
+	// once the batch succeeds, the file is replaced
+	config.Width = 100
+	if err := config.batch(strings.NewReader("SNAKE\n"), "text", existingDir); err != nil {
+		t.Fatalf("existing directory: batch returned error: %s", err)
+	}
+	checkPNG(t, filepath.Join(existingDir, "001-snake.png"), 100)
+	if files, err := ioutil.ReadDir(existingDir); err != nil || len(files) != len(existing) {
+		t.Errorf("existing directory holds %d files after succeeding, want %d", len(files), len(existing))
+	}
+}
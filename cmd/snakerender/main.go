@@ -0,0 +1,446 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image/color"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshbarrass/SnakeIsDead/pkg/letters"
+	"github.com/joshbarrass/SnakeIsDead/pkg/render"
+	"github.com/joshbarrass/SnakeIsDead/pkg/timeline"
+)
+
+const usage = `Usage: snakerender [flags] -o OUTPUT TEXT...
+       snakerender [flags] -batch MANIFEST -o DIRECTORY|ZIP
+
+Renders text as an animated phrase without a browser. The format is
+taken from the extension of the output unless -format is given, and an
+output of "-" writes to stdout.
+
+With -batch, every phrase in the manifest is rendered, or those read
+from stdin if it is "-". A text manifest has a phrase on each line. A
+JSON manifest is an array of objects, and a CSV manifest has a header
+row, both with the fields text, name, format, font, style, palette,
+foreground, background, width, height and padding. Anything an entry
+leaves out is taken from the flags, and the format is png if neither
+gives one. The files are written to the output directory, or into a
+zip file if the output ends in .zip or is "-". Entries without a name
+are named from their position in the manifest and their text. Nothing
+is replaced unless the whole batch is written.
+
+Formats:
+  png   the phrase once it has finished animating
+  apng  the animation as an animated PNG
+  gif   the animation as an animated GIF
+  svg   the finished phrase as vector artwork
+  pdf   the finished phrase as a poster, split across pages
+  y4m   the animation as uncompressed video, for piping to an encoder
+
+Flags:
+`
+
+// styles holds the built in styles by name
+var styles = map[string]letters.Style{
+	"light":   letters.LightStyle,
+	"regular": letters.RegularStyle,
+	"black":   letters.BlackStyle,
+}
+
+// pages holds the paper sizes posters can be printed on by name
+var pages = map[string]render.PageSize{
+	"a3":     render.PageA3,
+	"a4":     render.PageA4,
+	"letter": render.PageLetter,
+	"legal":  render.PageLegal,
+}
+
+// units holds the number of millimetres in each unit poster sizes can
+// be given in
+var units = map[string]float64{
+	"mm": 1,
+	"in": render.Inch,
+}
+
+// Configuration holds the flags controlling how phrases are rendered
+type Configuration struct {
+	Output    string
+	Format    string
+	Keyframes string
+	Batch     string
+	Manifest  string
+
+	Font       string
+	Style      string
+	Glyphs     string
+	Palette    string
+	Foreground string
+	Background string
+	Fallback   string
+
+	Width    float64
+	Height   float64
+	Padding  float64
+	FPS      float64
+	Duration time.Duration
+
+	Page      string
+	Landscape bool
+	Margin    float64
+	Overlap   float64
+	Units     string
+}
+
+// names returns the keys of a map in order, for listing in errors and
+// help
+func names(m interface{}) string {
+	keys := []string{}
+	for _, key := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
+
+// parseColor parses a colour given as hex, in the form #rgb or
+// #rrggbb. The # is optional.
+func parseColor(s string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 6 || err != nil {
+		return color.RGBA{}, fmt.Errorf("colour '%s' is not of the form #rrggbb", s)
+	}
+	return color.RGBA{uint8(value >> 16), uint8(value >> 8), uint8(value), 0xff}, nil
+}
+
+// colors returns the keyframe changing the colours of the palette
+// being used to the configured foreground and background, and
+// whether any were configured
+func (config *Configuration) colors(mode letters.ColorMode) (timeline.Keyframe, bool, error) {
+	if config.Foreground == "" && config.Background == "" {
+		return timeline.Keyframe{}, false, nil
+	}
+	colors := &timeline.Colors{Death: letters.ColorsDeath, Paradox: letters.ColorsParadox}
+	palette := &colors.Death
+	if mode == letters.ModeParadox {
+		palette = &colors.Paradox
+	}
+	for i, value := range []string{config.Background, config.Foreground} {
+		if value == "" {
+			continue
+		}
+		c, err := parseColor(value)
+		if err != nil {
+			return timeline.Keyframe{}, false, err
+		}
+		palette[i] = c
+	}
+	return timeline.Keyframe{Action: colors}, true, nil
+}
+
+// font returns the font to render with, in the configured style
+func (config *Configuration) font() (*letters.Font, error) {
+	font, ok := letters.LookupFont(config.Font)
+	if !ok {
+		return nil, fmt.Errorf("font '%s' not available, expected one of %s", config.Font, strings.Join(letters.RegisteredFonts(), ", "))
+	}
+	if config.Style == "" {
+		return font, nil
+	}
+	style, ok := styles[strings.ToLower(config.Style)]
+	if !ok {
+		return nil, fmt.Errorf("unknown style '%s', expected one of %s", config.Style, names(styles))
+	}
+	// the weights of the styles are for letters DefaultWidth wide, so
+	// the strokes are scaled to keep their proportions in the font
+	style = style.ScaledTo(font.Width, font.Height)
+	// the letters come from the font, and are drawn in the style
+	return letters.NewFont(font.Name+"-"+config.Style, font.Width, font.Height, style, font), nil
+}
+
+// keyframes returns the keyframes to render, which are either those in
+// the keyframes file or the phrase given
+func (config *Configuration) keyframes(text string) ([]timeline.Keyframe, error) {
+	mode, err := letters.ParseColorMode(config.Palette)
+	if err != nil {
+		return nil, err
+	}
+	// the palette is set before the phrase appears
+	keyframes := []timeline.Keyframe{{Action: &timeline.Mode{Mode: mode}}}
+	colors, ok, err := config.colors(mode)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		keyframes = append(keyframes, colors)
+	}
+
+	if config.Keyframes != "" {
+		f, err := os.Open(config.Keyframes)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		parsed, err := timeline.ParseKeyframes(f)
+		if err != nil {
+			return nil, err
+		}
+		return append(keyframes, parsed...), nil
+	}
+
+	font, err := config.font()
+	if err != nil {
+		return nil, err
+	}
+	policy, err := letters.ParseFallbackPolicy(config.Fallback)
+	if err != nil {
+		return nil, err
+	}
+	phrase, substitutions, err := timeline.NewPhrase(strings.ToUpper(text), font, policy)
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range substitutions {
+		if len(sub.Replacement) == 0 {
+			log.Printf("skipped '%c' at %d", sub.Original, sub.Index)
+		} else {
+			log.Printf("drew '%c' at %d as '%s'", sub.Original, sub.Index, string(sub.Replacement))
+		}
+	}
+	return append(keyframes, timeline.Keyframe{Action: phrase}), nil
+}
+
+// format returns the format to write to the given output
+func (config *Configuration) format(output string) (string, error) {
+	format := config.Format
+	if format == "" {
+		if output == "-" {
+			return "", fmt.Errorf("-format is needed when writing to stdout")
+		}
+		format = strings.TrimPrefix(filepath.Ext(output), ".")
+	}
+	format = strings.ToLower(format)
+	switch format {
+	case "png", "apng", "gif", "svg", "pdf", "y4m":
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown format '%s', expected one of png, apng, gif, svg, pdf, y4m", format)
+}
+
+// write renders the keyframes to w in the given format
+func (config *Configuration) write(w io.Writer, format string, keyframes []timeline.Keyframe) error {
+	if format == "pdf" {
+		page, ok := pages[strings.ToLower(config.Page)]
+		if !ok {
+			return fmt.Errorf("unknown page size '%s', expected one of %s", config.Page, names(pages))
+		}
+		unit, ok := units[strings.ToLower(config.Units)]
+		if !ok {
+			return fmt.Errorf("unknown units '%s', expected one of %s", config.Units, names(units))
+		}
+		return render.WritePoster(w, keyframes, render.PosterOptions{
+			Width:     config.Width * unit,
+			Height:    config.Height * unit,
+			Padding:   config.Padding * unit,
+			Page:      page,
+			Landscape: config.Landscape,
+			Margin:    config.Margin * unit,
+			Overlap:   config.Overlap * unit,
+			Duration:  config.Duration,
+		})
+	}
+
+	opts := render.Options{
+		Width:    int(config.Width),
+		Height:   int(config.Height),
+		Padding:  config.Padding,
+		FPS:      config.FPS,
+		Duration: config.Duration,
+	}
+	switch format {
+	case "png":
+		return render.WritePNG(w, keyframes, opts)
+	case "apng":
+		return render.WriteAPNG(w, keyframes, opts)
+	case "gif":
+		return render.WriteGIF(w, keyframes, opts)
+	case "svg":
+		return render.WriteSVG(w, keyframes, opts)
+	case "y4m":
+		return render.WriteY4M(w, keyframes, opts)
+	}
+	return fmt.Errorf("unknown format '%s'", format)
+}
+
+// render renders the text to the output path, or stdout if it is "-"
+func (config *Configuration) render(text, output string) error {
+	format, err := config.format(output)
+	if err != nil {
+		return err
+	}
+	keyframes, err := config.keyframes(text)
+	if err != nil {
+		return err
+	}
+	if output == "-" {
+		return config.write(os.Stdout, format, keyframes)
+	}
+	// the output is written under a temporary name, so that a file
+	// it would replace is left as it was if rendering fails
+	f, err := tempFile(output)
+	if err != nil {
+		return err
+	}
+	if err := config.write(f, format, keyframes); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), output)
+}
+
+// flags returns the command line flags, which are parsed into the
+// configuration
+func (config *Configuration) flags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.StringVar(&config.Output, "o", "", "output `path`, or - for stdout")
+	flags.StringVar(&config.Format, "format", "", "output `format`, instead of the output's extension")
+	flags.StringVar(&config.Keyframes, "keyframes", "", "render a keyframes JSON `file` instead of text, whose phrases give their own font and fallback")
+	flags.StringVar(&config.Batch, "batch", "", "render every phrase in a manifest `file`, or - for stdin")
+	flags.StringVar(&config.Manifest, "manifest", "", "`format` of the batch manifest: text, json or csv, instead of its extension")
+	flags.StringVar(&config.Font, "font", letters.DefaultFont.Name, "`name` of the font")
+	flags.StringVar(&config.Style, "style", "", "style to draw the font in: "+names(styles))
+	flags.StringVar(&config.Glyphs, "glyphs", "", "glyph definition `file` to add to the font")
+	flags.StringVar(&config.Palette, "palette", letters.ModeDeath.String(), "colour palette: death or paradox")
+	flags.StringVar(&config.Foreground, "foreground", "", "`colour` of the letters as #rrggbb, instead of the palette's")
+	flags.StringVar(&config.Background, "background", "", "`colour` behind the letters as #rrggbb, instead of the palette's")
+	flags.StringVar(&config.Fallback, "fallback", letters.FallbackError.String(), "`policy` for characters the font doesn't have: error, skip, tofu or decompose")
+	flags.Float64Var(&config.Width, "width", 0, "width in pixels, or in -units for pdf")
+	flags.Float64Var(&config.Height, "height", 0, "height in pixels, or in -units for pdf")
+	flags.Float64Var(&config.Padding, "padding", 20, "space around the phrase in pixels, or in -units for pdf")
+	flags.Float64Var(&config.FPS, "fps", render.DefaultFPS, "frames per second of animations")
+	flags.DurationVar(&config.Duration, "duration", 0, "how long to animate for, or until the animation finishes if 0")
+	flags.StringVar(&config.Page, "page", "a4", "page size for pdf: "+names(pages))
+	flags.BoolVar(&config.Landscape, "landscape", false, "turn pdf pages on their side")
+	flags.Float64Var(&config.Margin, "margin", 0, "unprinted margin holding the crop marks of pdf pages, in -units")
+	flags.Float64Var(&config.Overlap, "overlap", 0, "how much pdf pages overlap, in -units")
+	flags.StringVar(&config.Units, "units", "mm", "units of pdf sizes: "+names(units))
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("snakerender: ")
+
+	var config Configuration
+	flags := config.flags(os.Args[0])
+	if err := flags.Parse(os.Args[1:]); err == flag.ErrHelp {
+		os.Exit(0)
+	} else if err != nil {
+		os.Exit(2)
+	}
+
+	text := strings.Join(flags.Args(), " ")
+	if config.Output == "" || (text == "" && config.Keyframes == "" && config.Batch == "") {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err := config.run(text); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run renders the text, or the batch, as configured
+func (config *Configuration) run(text string) error {
+	if config.Batch != "" && (text != "" || config.Keyframes != "") {
+		return fmt.Errorf("-batch can't be used with text or -keyframes")
+	}
+	if config.Keyframes != "" {
+		// the phrases in a keyframes file give their own fonts and
+		// policies, so these would be ignored
+		conflicts := []string{}
+		if text != "" {
+			conflicts = append(conflicts, "text")
+		}
+		if config.Font != letters.DefaultFont.Name {
+			conflicts = append(conflicts, "-font")
+		}
+		if config.Style != "" {
+			conflicts = append(conflicts, "-style")
+		}
+		if config.Fallback != letters.FallbackError.String() {
+			conflicts = append(conflicts, "-fallback")
+		}
+		if len(conflicts) > 0 {
+			return fmt.Errorf("-keyframes can't be used with %s", strings.Join(conflicts, ", "))
+		}
+	}
+
+	if config.Glyphs != "" {
+		font, ok := letters.LookupFont(config.Font)
+		if !ok {
+			return fmt.Errorf("font '%s' not available, expected one of %s", config.Font, strings.Join(letters.RegisteredFonts(), ", "))
+		}
+		f, err := os.Open(config.Glyphs)
+		if err != nil {
+			return fmt.Errorf("could not open glyphs: %w", err)
+		}
+		err = font.LoadGlyphs(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("could not load glyphs: %w", err)
+		}
+	}
+
+	if config.Batch != "" {
+		manifest := io.Reader(os.Stdin)
+		format := config.Manifest
+		if config.Batch != "-" {
+			f, err := os.Open(config.Batch)
+			if err != nil {
+				return fmt.Errorf("could not open manifest: %w", err)
+			}
+			defer f.Close()
+			manifest = f
+			if format == "" {
+				switch strings.ToLower(filepath.Ext(config.Batch)) {
+				case ".json":
+					format = "json"
+				case ".csv":
+					format = "csv"
+				}
+			}
+		}
+		if format == "" {
+			format = "text"
+		}
+		if err := config.batch(manifest, strings.ToLower(format), config.Output); err != nil {
+			return fmt.Errorf("could not render batch: %w", err)
+		}
+		return nil
+	}
+
+	if err := config.render(text, config.Output); err != nil {
+		return fmt.Errorf("could not render: %w", err)
+	}
+	return nil
+}
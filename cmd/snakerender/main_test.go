@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/xml"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// runArgs parses the command line arguments and runs snakerender
+// with them, as main does
+func runArgs(t *testing.T, args ...string) error {
+	t.Helper()
+	var config Configuration
+	flags := config.flags("snakerender")
+	flags.SetOutput(ioutil.Discard)
+	if err := flags.Parse(args); err != nil {
+		t.Fatalf("could not parse %q: %s", args, err)
+	}
+	return config.run(strings.Join(flags.Args(), " "))
+}
+
+// checkPNG checks that the file is a PNG of the given width
+func checkPNG(t *testing.T, path string, width int) {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Errorf("could not decode %s as a PNG: %s", filepath.Base(path), err)
+		return
+	}
+	if got := img.Bounds().Dx(); got != width {
+		t.Errorf("%s is %d wide, want %d", filepath.Base(path), got, width)
+	}
+}
+
+// checkSVG checks that the file is an SVG document
+func checkSVG(t *testing.T, path string) {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	decoder := xml.NewDecoder(f)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			t.Errorf("could not find the root of %s: %s", filepath.Base(path), err)
+			return
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			if start.Name.Local != "svg" {
+				t.Errorf("%s has root element %s, want svg", filepath.Base(path), start.Name.Local)
+			}
+			return
+		}
+	}
+}
+
+func TestFlagDefaults(t *testing.T) {
+	var config Configuration
+	if err := config.flags("snakerender").Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := testConfig(); !reflect.DeepEqual(config, want) {
+		t.Errorf("default flags give %+v, want %+v", config, want)
+	}
+}
+
+func TestRunFormats(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	tests := []struct {
+		name   string
+		output string
+		args   []string
+		check  func(t *testing.T, path string)
+	}{
+		{"png flag", "png", []string{"-format", "png", "-width", "100"}, func(t *testing.T, path string) {
+			checkPNG(t, path, 100)
+		}},
+		{"svg flag", "svg", []string{"-format", "svg"}, checkSVG},
+		// the flag is used over the extension of the output
+		{"svg flag over extension", "flag.png", []string{"-format", "SVG"}, checkSVG},
+		{"png extension", "extension.png", []string{"-width", "120"}, func(t *testing.T, path string) {
+			checkPNG(t, path, 120)
+		}},
+		{"svg extension", "extension.svg", nil, checkSVG},
+	}
+	for _, test := range tests {
+		output := filepath.Join(dir, test.output)
+		args := append(test.args, "-o", output, "SNAKE", "IS", "DEAD")
+		if err := runArgs(t, args...); err != nil {
+			t.Errorf("%s: run returned error: %s", test.name, err)
+			continue
+		}
+		test.check(t, output)
+	}
+}
+
+func TestRunUnknownFormat(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"flag", []string{"-format", "bmp", "-o", filepath.Join(dir, "out.png")}, "unknown format 'bmp'"},
+		{"extension", []string{"-o", filepath.Join(dir, "out.bmp")}, "unknown format 'bmp'"},
+		{"stdout", []string{"-o", "-"}, "-format is needed"},
+	}
+	for _, test := range tests {
+		err := runArgs(t, append(test.args, "SNAKE")...)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: run returned error %v, want %q", test.name, err, test.want)
+		}
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("failed runs left %d files behind", len(files))
+	}
+}
+
+func TestRunFailureKeepsOutput(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	// the page size is only checked once the output is being written
+	output := filepath.Join(dir, "out.pdf")
+	if err := ioutil.WriteFile(output, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := runArgs(t, "-page", "b5", "-o", output, "SNAKE")
+	if err == nil || !strings.Contains(err.Error(), "unknown page size 'b5'") {
+		t.Errorf("run returned error %v, want one for the page size", err)
+	}
+	if contents, err := ioutil.ReadFile(output); err != nil || string(contents) != "old" {
+		t.Errorf("output holds %q after failing, want it left as it was", contents)
+	}
+	if files, err := ioutil.ReadDir(dir); err != nil || len(files) != 1 {
+		t.Errorf("failed run left %d files, want only the output", len(files))
+	}
+}
+
+func TestRunKeyframesConflicts(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	keyframes := filepath.Join(dir, "keyframes.json")
+	if err := ioutil.WriteFile(keyframes, []byte(`[{"at": 0, "action": "phrase", "text": "AB"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "out.png")
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"text", []string{"SNAKE"}, "-keyframes can't be used with text"},
+		{"font", []string{"-font", "other"}, "-keyframes can't be used with -font"},
+		{"style", []string{"-style", "light"}, "-keyframes can't be used with -style"},
+		{"fallback", []string{"-fallback", "skip"}, "-keyframes can't be used with -fallback"},
+		// an invalid policy is reported rather than ignored
+		{"invalid fallback", []string{"-fallback", "bogus"}, "-keyframes can't be used with -fallback"},
+		{"several", []string{"-font", "other", "-style", "light", "SNAKE"}, "-keyframes can't be used with text, -font, -style"},
+	}
+	for _, test := range tests {
+		args := append([]string{"-keyframes", keyframes, "-o", output}, test.args...)
+		err := runArgs(t, args...)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: run returned error %v, want %q", test.name, err, test.want)
+		}
+		if _, err := os.Stat(output); !os.IsNotExist(err) {
+			t.Errorf("%s: failed run wrote the output", test.name)
+		}
+	}
+
+	// the defaults given explicitly don't conflict
+	if err := runArgs(t, "-keyframes", keyframes, "-fallback", "error", "-width", "120", "-o", output); err != nil {
+		t.Fatalf("run returned error: %s", err)
+	}
+	checkPNG(t, output, 120)
+}
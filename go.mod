@@ -3,7 +3,9 @@ module github.com/joshbarrass/SnakeIsDead
 go 1.14
 
 require (
+	github.com/jung-kurt/gofpdf v1.0.0
 	github.com/kelseyhightower/envconfig v1.4.0
 	github.com/llgcode/draw2d v0.0.0-20200930101115-bfaf5d914d1e
 	github.com/markfarnan/go-canvas v0.0.0-20200722235510-6971ccd00770
+	golang.org/x/text v0.3.3
 )
@@ -2,9 +2,9 @@ package letters
 
 import (
 	"image/color"
+	"math"
 
 	"github.com/llgcode/draw2d"
-	"github.com/llgcode/draw2d/draw2dimg"
 )
 
 // CellDrawer represents a type that contains all of the drawing
@@ -16,25 +16,35 @@ type CellDrawer interface {
 	Close()
 	SetFillColor(c color.Color)
 	SetStrokeColor(c color.Color)
+	SetFillRule(f draw2d.FillRule)
 	MoveTo(x, y float64)
 	LineTo(x, y float64)
+	QuadCurveTo(cx, cy, x, y float64)
+	CubicCurveTo(cx1, cy1, cx2, cy2, x, y float64)
+	ArcTo(cx, cy, rx, ry, startAngle, angle float64)
 }
 
 // cellDrawer is the actual struct that implements the CellDrawer interface
 type cellDrawer struct {
-	GraphicContext *draw2dimg.GraphicContext
+	GraphicContext draw2d.GraphicContext
 	CellTopLeft    [2]float64
 	CellWidth      float64
 	CellHeight     float64
 	SegmentWidth   float64
 	SegmentHeight  float64
+
+	// drawing is whether a path has been started since the last fill
+	// or stroke
+	drawing bool
 }
 
 // NewCellDrawer creates a CellDrawer for allowing a segment to draw
 // within a cell. It allows the Cell and the segment's reference sizes
 // to be different, and will translate between one and the other to
-// simplify the construction of segment drawing procedures.
-func NewCellDrawer(gc *draw2dimg.GraphicContext, cell *Cell, segment Segment) CellDrawer {
+// simplify the construction of segment drawing procedures. The
+// GraphicContext can be any draw2d backend, such as draw2dimg for
+// images, or draw2dsvg and draw2dpdf for vector output.
+func NewCellDrawer(gc draw2d.GraphicContext, cell *Cell, segment Segment) CellDrawer {
 	return &cellDrawer{
 		GraphicContext: gc,
 		CellTopLeft:    cell.TopLeft,
@@ -48,21 +58,28 @@ func NewCellDrawer(gc *draw2dimg.GraphicContext, cell *Cell, segment Segment) Ce
 // Fill wraps GraphicContext.Fill
 func (cd *cellDrawer) Fill(paths ...*draw2d.Path) {
 	cd.GraphicContext.Fill(paths...)
+	cd.drawing = false
 }
 
 // FillStroke wraps GraphicContext.FillStroke
 func (cd *cellDrawer) FillStroke(paths ...*draw2d.Path) {
 	cd.GraphicContext.FillStroke(paths...)
+	cd.drawing = false
 }
 
 // Stroke wraps GraphicContext.Stroke
 func (cd *cellDrawer) Stroke(paths ...*draw2d.Path) {
 	cd.GraphicContext.Stroke(paths...)
+	cd.drawing = false
 }
 
-// Close wraps GraphicContext.Close
+// Close wraps GraphicContext.Close. Segments close their path after
+// filling it, which would leave a close on its own at the start of
+// the next path, so this is ignored if no path has been started.
 func (cd *cellDrawer) Close() {
-	cd.GraphicContext.Close()
+	if cd.drawing {
+		cd.GraphicContext.Close()
+	}
 }
 
 // SetFillColor wraps GraphicContext.SetFillColor
@@ -75,6 +92,11 @@ func (cd *cellDrawer) SetStrokeColor(c color.Color) {
 	cd.GraphicContext.SetStrokeColor(c)
 }
 
+// SetFillRule wraps GraphicContext.SetFillRule
+func (cd *cellDrawer) SetFillRule(f draw2d.FillRule) {
+	cd.GraphicContext.SetFillRule(f)
+}
+
 // ConvertCoords converts coordinates from the segment system to the global cell system
 func (cd *cellDrawer) ConvertCoords(x, y float64) (newX, newY float64) {
 	// (x, y) is in the segment coordinate system
@@ -90,10 +112,69 @@ func (cd *cellDrawer) ConvertCoords(x, y float64) (newX, newY float64) {
 func (cd *cellDrawer) MoveTo(x, y float64) {
 	newX, newY := cd.ConvertCoords(x, y)
 	cd.GraphicContext.MoveTo(newX, newY)
+	cd.drawing = true
 }
 
 // LineTo wraps GraphicContext.LineTo, converting between coordinate systems in the process
 func (cd *cellDrawer) LineTo(x, y float64) {
 	newX, newY := cd.ConvertCoords(x, y)
 	cd.GraphicContext.LineTo(newX, newY)
+	cd.drawing = true
+}
+
+// ConvertLengths converts horizontal and vertical lengths from the
+// segment system to the global cell system
+func (cd *cellDrawer) ConvertLengths(dx, dy float64) (newDx, newDy float64) {
+	newDx = dx * (cd.CellWidth - 1) / (cd.SegmentWidth - 1)
+	newDy = dy * (cd.CellHeight - 1) / (cd.SegmentHeight - 1)
+	return
+}
+
+// QuadCurveTo wraps GraphicContext.QuadCurveTo, converting between
+// coordinate systems in the process
+func (cd *cellDrawer) QuadCurveTo(cx, cy, x, y float64) {
+	newCx, newCy := cd.ConvertCoords(cx, cy)
+	newX, newY := cd.ConvertCoords(x, y)
+	cd.GraphicContext.QuadCurveTo(newCx, newCy, newX, newY)
+	cd.drawing = true
+}
+
+// CubicCurveTo wraps GraphicContext.CubicCurveTo, converting between
+// coordinate systems in the process
+func (cd *cellDrawer) CubicCurveTo(cx1, cy1, cx2, cy2, x, y float64) {
+	newCx1, newCy1 := cd.ConvertCoords(cx1, cy1)
+	newCx2, newCy2 := cd.ConvertCoords(cx2, cy2)
+	newX, newY := cd.ConvertCoords(x, y)
+	cd.GraphicContext.CubicCurveTo(newCx1, newCy1, newCx2, newCy2, newX, newY)
+	cd.drawing = true
+}
+
+// ArcTo wraps GraphicContext.ArcTo, converting between coordinate
+// systems in the process. The arc is centred on (cx, cy) with radii
+// rx and ry, and the angles are in radians. As the cell may be
+// stretched differently to the segment in each direction, the radii
+// are scaled separately, so a circular arc may become elliptical.
+func (cd *cellDrawer) ArcTo(cx, cy, rx, ry, startAngle, angle float64) {
+	newCx, newCy := cd.ConvertCoords(cx, cy)
+	newRx, newRy := cd.ConvertLengths(rx, ry)
+	cd.GraphicContext.ArcTo(newCx, newCy, newRx, newRy, startAngle, angle)
+	cd.drawing = true
+}
+
+// ellipseKappa is how far along the tangents the control points of a
+// cubic curve are put, as a fraction of the radius, for the curve to
+// follow a quarter of an ellipse
+var ellipseKappa = 4 * (math.Sqrt2 - 1) / 3
+
+// ellipseCornerTo draws a quarter of an ellipse from (x0, y0) to
+// (x, y), which bulges towards the corner (cornerX, cornerY). The
+// corner must line up with the start on one axis and with the end on
+// the other, so that the curve leaves and joins the edges of the
+// segment smoothly.
+func ellipseCornerTo(gc CellDrawer, x0, y0, cornerX, cornerY, x, y float64) {
+	gc.CubicCurveTo(
+		x0+ellipseKappa*(cornerX-x0), y0+ellipseKappa*(cornerY-y0),
+		x+ellipseKappa*(cornerX-x), y+ellipseKappa*(cornerY-y),
+		x, y,
+	)
 }
@@ -0,0 +1,139 @@
+package letters
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image"
+	"image/color"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dimg"
+	"github.com/llgcode/draw2d/draw2dpdf"
+	"github.com/llgcode/draw2d/draw2dsvg"
+)
+
+func TestCellDrawerConversion(t *testing.T) {
+	// the cell is twice as wide and three times as tall as the
+	// segment, and is moved away from the origin
+	cell := NewCell([2]float64{10, 20}, [2]float64{10 + 2*(DefaultWidth-1) + 1, 20 + 3*(DefaultHeight-1) + 1}, nil, ColorsDeath, ColorsParadox, DefaultFont)
+	seg := &SegmentNBar{}
+	draw := func(cd CellDrawer) {
+		cd.MoveTo(0, 0)
+		cd.LineTo(5, 1)
+		cd.QuadCurveTo(5, 4, 10, 8)
+		cd.CubicCurveTo(11, 9, 12, 10, 13, 11)
+		cd.ArcTo(25, 42, 10, 5, 0, math.Pi)
+	}
+	wantComponents := []draw2d.PathCmp{
+		draw2d.MoveToCmp, draw2d.LineToCmp, draw2d.QuadCurveToCmp, draw2d.CubicCurveToCmp,
+		// draw2d joins the current point to the start of the arc
+		draw2d.LineToCmp, draw2d.ArcToCmp,
+	}
+	wantPoints := []float64{
+		10, 20,
+		20, 23,
+		20, 32, 30, 44,
+		32, 47, 34, 50, 36, 53,
+		// the start of the arc, right of its centre
+		80, 146,
+		// the centre is converted as a point, and the radii as
+		// lengths, so they are scaled but not moved
+		60, 146, 20, 15, 0, math.Pi,
+	}
+
+	tests := []struct {
+		name string
+		path func() *draw2d.Path
+	}{
+		{"cellDrawer", func() *draw2d.Path {
+			gc := draw2dimg.NewGraphicContext(image.NewRGBA(image.Rect(0, 0, 1, 1)))
+			draw(NewCellDrawer(gc, cell, seg))
+			return gc.Current.Path
+		}},
+		{"pathRecorder", func() *draw2d.Path {
+			rec := newPathRecorder(cell, seg)
+			draw(rec)
+			return &rec.path
+		}},
+	}
+	for _, test := range tests {
+		path := test.path()
+		if !reflect.DeepEqual(path.Components, wantComponents) {
+			t.Errorf("%s: path has components %v, want %v", test.name, path.Components, wantComponents)
+		}
+		if !reflect.DeepEqual(path.Points, wantPoints) {
+			t.Errorf("%s: path has points %v, want %v", test.name, path.Points, wantPoints)
+		}
+	}
+}
+
+// fill is a path filled by a graphic context, and the colour it was
+// filled with
+type fill struct {
+	path  draw2d.Path
+	color color.Color
+}
+
+// fillRecorder wraps a graphic context, recording the paths it fills
+type fillRecorder struct {
+	draw2d.GraphicContext
+	color color.Color
+	fills []fill
+}
+
+// SetFillColor records the colour and passes it on
+func (rec *fillRecorder) SetFillColor(c color.Color) {
+	rec.color = c
+	rec.GraphicContext.SetFillColor(c)
+}
+
+// Fill records the current path and passes it on
+func (rec *fillRecorder) Fill(paths ...*draw2d.Path) {
+	rec.fills = append(rec.fills, fill{rec.GetPath(), rec.color})
+	rec.GraphicContext.Fill(paths...)
+}
+
+func TestCellDrawAnyContext(t *testing.T) {
+	// the cell is drawn solid straight away, so that each backend is
+	// given the same paths in the same colours
+	drawCell := func(gc draw2d.GraphicContext) []fill {
+		letterFunc, _ := DefaultFont.LookupLetter('A')
+		cell := NewCell([2]float64{10, 20}, [2]float64{10 + DefaultWidth, 20 + DefaultHeight}, letterFunc(), ColorsDeath, ColorsParadox, DefaultFont)
+		cell.Lifecycle = Lifecycle{}
+		cell.Clock = NewManualClock(time.Unix(0, 0))
+		rec := &fillRecorder{GraphicContext: gc}
+		cell.Draw(rec)
+		return rec.fills
+	}
+	want := drawCell(draw2dimg.NewGraphicContext(image.NewRGBA(image.Rect(0, 0, 100, 100))))
+	if len(want) == 0 {
+		t.Fatal("cell filled nothing onto an image")
+	}
+
+	pdf := gofpdf.New("P", "pt", "A4", "")
+	pdf.AddPage()
+	if got := drawCell(draw2dpdf.NewGraphicContext(pdf)); !reflect.DeepEqual(got, want) {
+		t.Errorf("cell filled %v onto a PDF, want %v", got, want)
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Errorf("could not write PDF: %s", err)
+	}
+
+	svg := draw2dsvg.NewSvg()
+	if got := drawCell(draw2dsvg.NewGraphicContext(svg)); !reflect.DeepEqual(got, want) {
+		t.Errorf("cell filled %v onto an SVG, want %v", got, want)
+	}
+	out, err := xml.Marshal(svg)
+	if err != nil {
+		t.Fatalf("could not write SVG: %s", err)
+	}
+	if paths := bytes.Count(out, []byte("<path")); paths != len(want) {
+		t.Errorf("SVG has %d paths, want %d", paths, len(want))
+	}
+}
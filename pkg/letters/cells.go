@@ -2,8 +2,9 @@ package letters
 
 import (
 	"image/color"
+	"time"
 
-	"github.com/llgcode/draw2d/draw2dimg"
+	"github.com/llgcode/draw2d"
 )
 
 // Cell represents a single cell containing LetterSegments
@@ -14,12 +15,25 @@ type Cell struct {
 	DeathColors   [2]color.RGBA
 	ParadoxColors [2]color.RGBA
 	LetterColor   color.Color
+	Font          *Font
+	Style         Style
+	Lifecycle     Lifecycle
+	Mode          ColorMode
+	// Clock is the time source for the cell's animations, which is
+	// the real time if it is nil
+	Clock         Clock
+	MorphProgress float64
+	transition    colorTransition
+	morph         *outlineMorph
+	dirty         bool
+	last          drawState
 }
 
 // NewCell creates a new Cell. The color arguments take an array of
 // two colors, the first is the background and the second is the
-// foreground.
-func NewCell(topLeft, bottomRight [2]float64, segments []Segment, deathColors, paradoxColors [2]color.RGBA) *Cell {
+// foreground. The segments are drawn using the metrics and style of
+// the font, or DefaultFont if it is nil.
+func NewCell(topLeft, bottomRight [2]float64, segments []Segment, deathColors, paradoxColors [2]color.RGBA, font *Font) *Cell {
 	// fix the order if necessary
 	if topLeft[0] > bottomRight[0] {
 		topLeft[0], bottomRight[0] = bottomRight[0], topLeft[0]
@@ -28,6 +42,16 @@ func NewCell(topLeft, bottomRight [2]float64, segments []Segment, deathColors, p
 		topLeft[1], bottomRight[1] = bottomRight[1], topLeft[1]
 	}
 
+	if font == nil {
+		font = DefaultFont
+	}
+	// segments without their own size are designed on the font's cell
+	for _, seg := range segments {
+		if r, ok := seg.(resizer); ok {
+			r.resize(font.Width, font.Height)
+		}
+	}
+
 	// return the cell
 	return &Cell{
 		TopLeft:       topLeft,
@@ -35,10 +59,22 @@ func NewCell(topLeft, bottomRight [2]float64, segments []Segment, deathColors, p
 		Segments:      segments,
 		DeathColors:   deathColors,
 		ParadoxColors: paradoxColors,
-		LetterColor:   deathColors[0],
+		LetterColor:   deathColors[1],
+		Font:          font,
+		Style:         font.Style,
+		Lifecycle:     DefaultLifecycle,
+		dirty:         true,
 	}
 }
 
+// now returns the current time according to the cell's clock
+func (cell *Cell) now() time.Time {
+	if cell.Clock == nil {
+		return time.Now()
+	}
+	return cell.Clock.Now()
+}
+
 // Width returns the cell's width
 func (cell *Cell) Width() float64 {
 	return cell.BottomRight[0] - cell.TopLeft[0]
@@ -49,6 +85,17 @@ func (cell *Cell) Height() float64 {
 	return cell.BottomRight[1] - cell.TopLeft[1]
 }
 
+// AdvanceWidth returns how much horizontal space the cell's letter
+// takes up, in the coordinates of its font, when drawn in the cell's
+// style
+func (cell *Cell) AdvanceWidth() float64 {
+	font := cell.Font
+	if font == nil {
+		font = DefaultFont
+	}
+	return font.AdvanceWidthIn(cell.Segments, &cell.Style)
+}
+
 // Scale increases the size of the cell, keeping the centre the same
 func (cell *Cell) Scale(factor float64) {
 	width := cell.Width()
@@ -71,14 +118,80 @@ func (cell *Cell) Translate(x, y float64) {
 }
 
 // Draw calls the Draw method for all of its segments for them to
-// render onto it. The image is then rendered onto the main context.
-func (cell *Cell) Draw(gc *draw2dimg.GraphicContext) bool {
-	hasChanged := false
+// render onto it, using any draw2d backend. It returns whether the
+// cell is still changing, and so will need drawing again.
+func (cell *Cell) Draw(gc draw2d.GraphicContext) bool {
+	now := cell.now()
+	colors, hasChanged := cell.colorsAt(now)
+	cell.LetterColor = colors[1]
 	gc.SetStrokeColor(color.RGBA{0x00, 0x00, 0x00, 0x00})
+	if cell.morph != nil {
+		if cell.morph.Draw(NewCellDrawer(gc, cell, cell.morph), cell) {
+			hasChanged = true
+		}
+		cell.drawn(hasChanged)
+		return hasChanged
+	}
 	for _, seg := range cell.Segments {
 		if seg.Draw(NewCellDrawer(gc, cell, seg), cell) {
 			hasChanged = true
 		}
 	}
+	cell.removeFaded(now)
+	cell.drawn(hasChanged)
 	return hasChanged
 }
+
+// DrawBackground fills the cell with its current background colour
+func (cell *Cell) DrawBackground(gc draw2d.GraphicContext) {
+	colors, _ := cell.colorsAt(cell.now())
+	gc.SetFillColor(colors[0])
+	gc.MoveTo(cell.TopLeft[0], cell.TopLeft[1])
+	gc.LineTo(cell.BottomRight[0], cell.TopLeft[1])
+	gc.LineTo(cell.BottomRight[0], cell.BottomRight[1])
+	gc.LineTo(cell.TopLeft[0], cell.BottomRight[1])
+	gc.Close()
+	gc.Fill()
+}
+
+// FadeOut starts all of the cell's segments fading out
+func (cell *Cell) FadeOut() {
+	cell.dirty = true
+	now := cell.now()
+	for _, seg := range cell.Segments {
+		if l, ok := seg.(lifecycler); ok {
+			l.fadeOut(now, cell.Lifecycle)
+		}
+	}
+}
+
+// FadeOutSegments starts the cell's segments with any of the given
+// IDs fading out
+func (cell *Cell) FadeOutSegments(ids ...SegmentID) {
+	cell.dirty = true
+	now := cell.now()
+	for _, seg := range cell.Segments {
+		l, ok := seg.(lifecycler)
+		if !ok {
+			continue
+		}
+		for _, id := range ids {
+			if seg.ID() == id {
+				l.fadeOut(now, cell.Lifecycle)
+				break
+			}
+		}
+	}
+}
+
+// Faded returns whether all of the cell's segments have finished
+// fading out
+func (cell *Cell) Faded() bool {
+	now := cell.now()
+	for _, seg := range cell.Segments {
+		if l, ok := seg.(lifecycler); ok && !l.faded(now, cell.Lifecycle) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package letters
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for animations. Giving cells a clock
+// other than the real one allows frames to be stepped through
+// deterministically, such as when exporting an animation.
+type Clock interface {
+	Now() time.Time
+}
+
+// RealClock is a Clock that tells the actual time
+type RealClock struct{}
+
+// Now returns the current time
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// FixedStepClock is a Clock that moves forward by the same step
+// each frame, regardless of how long the frame actually took
+type FixedStepClock struct {
+	Step time.Duration
+
+	lock    sync.Mutex
+	current time.Time
+}
+
+// NewFixedStepClock creates a FixedStepClock starting at the given
+// time
+func NewFixedStepClock(start time.Time, step time.Duration) *FixedStepClock {
+	return &FixedStepClock{
+		Step:    step,
+		current: start,
+	}
+}
+
+// NewFrameClock creates a FixedStepClock that steps by one frame at
+// the given frame rate, starting at the zero Unix time
+func NewFrameClock(fps float64) *FixedStepClock {
+	return NewFixedStepClock(time.Unix(0, 0), time.Duration(float64(time.Second)/fps))
+}
+
+// Now returns the time of the current frame
+func (clock *FixedStepClock) Now() time.Time {
+	clock.lock.Lock()
+	defer clock.lock.Unlock()
+	return clock.current
+}
+
+// Tick moves the clock on to the next frame, and returns its time
+func (clock *FixedStepClock) Tick() time.Time {
+	clock.lock.Lock()
+	defer clock.lock.Unlock()
+	clock.current = clock.current.Add(clock.Step)
+	return clock.current
+}
+
+// ManualClock is a Clock that only changes when it is told to
+type ManualClock struct {
+	lock    sync.Mutex
+	current time.Time
+}
+
+// NewManualClock creates a ManualClock set to the given time
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{
+		current: start,
+	}
+}
+
+// Now returns the time the clock is set to
+func (clock *ManualClock) Now() time.Time {
+	clock.lock.Lock()
+	defer clock.lock.Unlock()
+	return clock.current
+}
+
+// Set sets the clock to the given time
+func (clock *ManualClock) Set(t time.Time) {
+	clock.lock.Lock()
+	defer clock.lock.Unlock()
+	clock.current = t
+}
+
+// Advance moves the clock forward by the given duration
+func (clock *ManualClock) Advance(d time.Duration) {
+	clock.lock.Lock()
+	defer clock.lock.Unlock()
+	clock.current = clock.current.Add(d)
+}
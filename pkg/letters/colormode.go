@@ -0,0 +1,121 @@
+package letters
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+)
+
+// ColorMode selects which of a cell's palettes it is drawn with
+type ColorMode int
+
+// Possible colour modes
+const (
+	// ModeDeath draws the cell with its DeathColors
+	ModeDeath ColorMode = iota
+	// ModeParadox draws the cell with its ParadoxColors
+	ModeParadox
+)
+
+var colorModeNames = []string{"death", "paradox"}
+
+// String returns the name of the mode
+func (mode ColorMode) String() string {
+	if mode < 0 || int(mode) >= len(colorModeNames) {
+		return fmt.Sprintf("ColorMode(%d)", int(mode))
+	}
+	return colorModeNames[mode]
+}
+
+// ParseColorMode returns the mode with the given name
+func ParseColorMode(name string) (ColorMode, error) {
+	for i, modeName := range colorModeNames {
+		if strings.EqualFold(name, modeName) {
+			return ColorMode(i), nil
+		}
+	}
+	return ModeDeath, fmt.Errorf("unknown colour mode '%s', expected one of %s", name, strings.Join(colorModeNames, ", "))
+}
+
+// colorTransition holds the progress of a cell changing from one
+// palette to another
+type colorTransition struct {
+	from     [2]color.RGBA
+	start    time.Time
+	duration time.Duration
+}
+
+// palette returns the colours the cell uses in the given mode
+func (cell *Cell) palette(mode ColorMode) [2]color.RGBA {
+	if mode == ModeParadox {
+		return cell.ParadoxColors
+	}
+	return cell.DeathColors
+}
+
+// colorsAt returns the cell's background and foreground colours at
+// the given time, and whether they are still changing
+func (cell *Cell) colorsAt(now time.Time) ([2]color.RGBA, bool) {
+	target := cell.palette(cell.Mode)
+	elapsed := now.Sub(cell.transition.start)
+	if cell.transition.duration <= 0 || elapsed >= cell.transition.duration {
+		return target, false
+	}
+	t := float64(elapsed) / float64(cell.transition.duration)
+	if t < 0 {
+		t = 0
+	}
+	return [2]color.RGBA{
+		lerpColor(cell.transition.from[0], target[0], t),
+		lerpColor(cell.transition.from[1], target[1], t),
+	}, true
+}
+
+// Colors returns the background and foreground colours the cell is
+// currently drawn with, part way between the two palettes if it is
+// changing mode
+func (cell *Cell) Colors() [2]color.RGBA {
+	colors, _ := cell.colorsAt(cell.now())
+	return colors
+}
+
+// SetMode changes the palette the cell is drawn with, fading from
+// its current colours over the given duration. A duration of zero
+// switches immediately.
+func (cell *Cell) SetMode(mode ColorMode, duration time.Duration) {
+	now := cell.now()
+	from, _ := cell.colorsAt(now)
+	cell.Mode = mode
+	cell.dirty = true
+	cell.transition = colorTransition{
+		from:     from,
+		start:    now,
+		duration: duration,
+	}
+}
+
+// SetPalettes replaces the colours the cell is drawn with in each
+// mode
+func (cell *Cell) SetPalettes(deathColors, paradoxColors [2]color.RGBA) {
+	cell.DeathColors = deathColors
+	cell.ParadoxColors = paradoxColors
+	cell.dirty = true
+}
+
+// SetCellsMode changes the mode of all of the cells, such as those
+// making up a phrase
+func SetCellsMode(cells []*Cell, mode ColorMode, duration time.Duration) {
+	for _, cell := range cells {
+		cell.SetMode(mode, duration)
+	}
+}
+
+// lerpColor linearly interpolates between two colours, where t is
+// between 0 and 1
+func lerpColor(a, b color.RGBA, t float64) color.RGBA {
+	lerp := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*t + 0.5)
+	}
+	return color.RGBA{lerp(a.R, b.R), lerp(a.G, b.G), lerp(a.B, b.B), lerp(a.A, b.A)}
+}
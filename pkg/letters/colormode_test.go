@@ -0,0 +1,107 @@
+package letters
+
+import (
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestLerpColor(t *testing.T) {
+	a := color.RGBA{0, 10, 200, 0xff}
+	b := color.RGBA{255, 100, 0, 0x7f}
+	tests := []struct {
+		t    float64
+		want color.RGBA
+	}{
+		{0, a},
+		{1, b},
+		// halves round up
+		{0.5, color.RGBA{128, 55, 100, 0xbf}},
+		{0.25, color.RGBA{64, 33, 150, 0xdf}},
+	}
+	for _, test := range tests {
+		if got := lerpColor(a, b, test.t); got != test.want {
+			t.Errorf("lerpColor(%v, %v, %v) = %v, want %v", a, b, test.t, got, test.want)
+		}
+	}
+}
+
+func TestSetMode(t *testing.T) {
+	death := [2]color.RGBA{{0, 0, 0, 0xff}, {200, 100, 0, 0xff}}
+	paradox := [2]color.RGBA{{100, 50, 255, 0xff}, {0, 0, 0, 0xff}}
+	halfway := [2]color.RGBA{{50, 25, 128, 0xff}, {100, 50, 0, 0xff}}
+	duration := 400 * time.Millisecond
+
+	tests := []struct {
+		name string
+		// set changes the mode of some of the cells, and want is the
+		// colours of each cell at each time
+		set  func(cells []*Cell)
+		at   []time.Duration
+		want [][][2]color.RGBA
+	}{
+		{
+			name: "phrase",
+			set:  func(cells []*Cell) { SetCellsMode(cells, ModeParadox, duration) },
+			at:   []time.Duration{0, duration / 2, duration, 2 * duration},
+			want: [][][2]color.RGBA{
+				{death, death},
+				{halfway, halfway},
+				{paradox, paradox},
+				{paradox, paradox},
+			},
+		},
+		{
+			name: "cell",
+			set:  func(cells []*Cell) { cells[1].SetMode(ModeParadox, duration) },
+			at:   []time.Duration{0, duration / 2, duration},
+			want: [][][2]color.RGBA{
+				{death, death},
+				{death, halfway},
+				{death, paradox},
+			},
+		},
+		{
+			name: "immediate",
+			set:  func(cells []*Cell) { SetCellsMode(cells, ModeParadox, 0) },
+			at:   []time.Duration{0},
+			want: [][][2]color.RGBA{{paradox, paradox}},
+		},
+		{
+			// changing back part way through fades from the colours
+			// the cell had reached
+			name: "reversed",
+			set: func(cells []*Cell) {
+				clock := cells[0].Clock.(*ManualClock)
+				SetCellsMode(cells, ModeParadox, duration)
+				clock.Advance(duration / 2)
+				SetCellsMode(cells[:1], ModeDeath, duration)
+			},
+			at: []time.Duration{duration / 2, duration, 3 * duration / 2},
+			want: [][][2]color.RGBA{
+				{halfway, halfway},
+				{{{25, 13, 64, 0xff}, {150, 75, 0, 0xff}}, paradox},
+				{death, paradox},
+			},
+		},
+	}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, test := range tests {
+		clock := NewManualClock(start)
+		cells := []*Cell{}
+		for i := 0; i < 2; i++ {
+			cell := NewCell([2]float64{float64(i) * DefaultWidth, 0}, [2]float64{float64(i+1) * DefaultWidth, DefaultHeight}, nil, death, paradox, DefaultFont)
+			cell.Clock = clock
+			cells = append(cells, cell)
+		}
+		test.set(cells)
+		for i, at := range test.at {
+			clock.Set(start.Add(at))
+			for j, cell := range cells {
+				if got := cell.Colors(); got != test.want[i][j] {
+					t.Errorf("%s: cell %d has colours %v after %v, want %v", test.name, j+1, got, at, test.want[i][j])
+				}
+			}
+		}
+	}
+}
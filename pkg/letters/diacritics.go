@@ -0,0 +1,274 @@
+package letters
+
+import "math"
+
+// Accented returns a function that builds the base letter with the
+// segments of a diacritic added to it
+func Accented(base func() Letter, diacritic func() Letter) func() Letter {
+	return func() Letter {
+		return append(base(), diacritic()...)
+	}
+}
+
+/* Acute */
+
+// SegmentAcute is an acute accent above the letter
+type SegmentAcute struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentAcute) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	centre := seg.Width()/2 - 1
+	gc.MoveTo(centre-style.AccentSlant-style.AccentThickness/2, style.accentBottom())
+	gc.LineTo(centre-style.AccentSlant+style.AccentThickness/2, style.accentBottom())
+	gc.LineTo(centre+style.AccentSlant+style.AccentThickness/2, style.accentTop())
+	gc.LineTo(centre+style.AccentSlant-style.AccentThickness/2, style.accentTop())
+	gc.LineTo(centre-style.AccentSlant-style.AccentThickness/2, style.accentBottom())
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// extent returns how far the segment reaches outside of the cell
+func (seg *SegmentAcute) extent(style *Style) (above, below float64) {
+	return -style.accentTop(), 0
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentAcute) ID() SegmentID { return IDAcute }
+
+// DiacriticAcute returns the segments for an acute accent
+func DiacriticAcute() Letter {
+	return Letter{&SegmentAcute{}}
+}
+
+/* Grave */
+
+// SegmentGrave is a grave accent above the letter
+type SegmentGrave struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentGrave) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	centre := seg.Width()/2 - 1
+	gc.MoveTo(centre+style.AccentSlant+style.AccentThickness/2, style.accentBottom())
+	gc.LineTo(centre+style.AccentSlant-style.AccentThickness/2, style.accentBottom())
+	gc.LineTo(centre-style.AccentSlant-style.AccentThickness/2, style.accentTop())
+	gc.LineTo(centre-style.AccentSlant+style.AccentThickness/2, style.accentTop())
+	gc.LineTo(centre+style.AccentSlant+style.AccentThickness/2, style.accentBottom())
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// extent returns how far the segment reaches outside of the cell
+func (seg *SegmentGrave) extent(style *Style) (above, below float64) {
+	return -style.accentTop(), 0
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentGrave) ID() SegmentID { return IDGrave }
+
+// DiacriticGrave returns the segments for a grave accent
+func DiacriticGrave() Letter {
+	return Letter{&SegmentGrave{}}
+}
+
+/* Circumflex */
+
+// SegmentCircumflex is a circumflex above the letter
+type SegmentCircumflex struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentCircumflex) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	centre := seg.Width()/2 - 1
+	gc.MoveTo(centre-style.CircumflexWidth, style.accentBottom())
+	gc.LineTo(centre-style.CircumflexWidth+style.AccentThickness, style.accentBottom())
+	gc.LineTo(centre, style.accentThickInner())
+	gc.LineTo(centre+style.CircumflexWidth-style.AccentThickness, style.accentBottom())
+	gc.LineTo(centre+style.CircumflexWidth, style.accentBottom())
+	gc.LineTo(centre, style.accentTop())
+	gc.LineTo(centre-style.CircumflexWidth, style.accentBottom())
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// extent returns how far the segment reaches outside of the cell
+func (seg *SegmentCircumflex) extent(style *Style) (above, below float64) {
+	return -style.accentTop(), 0
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentCircumflex) ID() SegmentID { return IDCircumflex }
+
+// DiacriticCircumflex returns the segments for a circumflex
+func DiacriticCircumflex() Letter {
+	return Letter{&SegmentCircumflex{}}
+}
+
+/* Diaeresis */
+
+// SegmentDiaeresis is the pair of dots of a diaeresis or umlaut above
+// the letter
+type SegmentDiaeresis struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentDiaeresis) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	centre := seg.Width()/2 - 1
+	gc.MoveTo(centre-style.DiaeresisGap/2-style.AccentThickness, style.accentThickTop())
+	gc.LineTo(centre-style.DiaeresisGap/2, style.accentThickTop())
+	gc.LineTo(centre-style.DiaeresisGap/2, style.accentBottom())
+	gc.LineTo(centre-style.DiaeresisGap/2-style.AccentThickness, style.accentBottom())
+	gc.LineTo(centre-style.DiaeresisGap/2-style.AccentThickness, style.accentThickTop())
+	gc.MoveTo(centre+style.DiaeresisGap/2, style.accentThickTop())
+	gc.LineTo(centre+style.DiaeresisGap/2+style.AccentThickness, style.accentThickTop())
+	gc.LineTo(centre+style.DiaeresisGap/2+style.AccentThickness, style.accentBottom())
+	gc.LineTo(centre+style.DiaeresisGap/2, style.accentBottom())
+	gc.LineTo(centre+style.DiaeresisGap/2, style.accentThickTop())
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// extent returns how far the segment reaches outside of the cell
+func (seg *SegmentDiaeresis) extent(style *Style) (above, below float64) {
+	return -style.accentTop(), 0
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentDiaeresis) ID() SegmentID { return IDDiaeresis }
+
+// DiacriticDiaeresis returns the segments for a diaeresis
+func DiacriticDiaeresis() Letter {
+	return Letter{&SegmentDiaeresis{}}
+}
+
+/* Tilde */
+
+// SegmentTilde is a tilde above the letter
+type SegmentTilde struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentTilde) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	centre := seg.Width()/2 - 1
+	gc.MoveTo(centre-style.TildeWidth, style.accentBottom())
+	gc.LineTo(centre-style.TildeWidth, style.accentThickTop())
+	gc.LineTo(centre-style.TildeWidth/3, style.accentTop())
+	gc.LineTo(centre+style.TildeWidth/3, style.accentThickTop())
+	gc.LineTo(centre+style.TildeWidth, style.accentTop())
+	gc.LineTo(centre+style.TildeWidth, style.accentThickInner())
+	gc.LineTo(centre+style.TildeWidth/3, style.accentBottom())
+	gc.LineTo(centre-style.TildeWidth/3, style.accentThickInner())
+	gc.LineTo(centre-style.TildeWidth, style.accentBottom())
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// extent returns how far the segment reaches outside of the cell
+func (seg *SegmentTilde) extent(style *Style) (above, below float64) {
+	return -style.accentTop(), 0
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentTilde) ID() SegmentID { return IDTilde }
+
+// DiacriticTilde returns the segments for a tilde
+func DiacriticTilde() Letter {
+	return Letter{&SegmentTilde{}}
+}
+
+/* Ring */
+
+// SegmentRing is the ring above an A. It is an ellipse RingSize wide
+// which fills the height of the other diacritics.
+type SegmentRing struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentRing) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	cx, cy := seg.Width()/2-1, (style.accentTop()+style.accentBottom())/2
+	rx, ry := style.RingSize/2, (style.accentBottom()-style.accentTop())/2
+	// outside, clockwise
+	gc.MoveTo(cx+rx, cy)
+	gc.ArcTo(cx, cy, rx, ry, 0, 2*math.Pi)
+	// inside, anticlockwise
+	gc.MoveTo(cx+rx-style.RingThickness, cy)
+	gc.ArcTo(cx, cy, rx-style.RingThickness, ry-style.RingThickness, 0, -2*math.Pi)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// extent returns how far the segment reaches outside of the cell
+func (seg *SegmentRing) extent(style *Style) (above, below float64) {
+	return -style.accentTop(), 0
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentRing) ID() SegmentID { return IDRing }
+
+// DiacriticRing returns the segments for a ring
+func DiacriticRing() Letter {
+	return Letter{&SegmentRing{}}
+}
+
+/* Cedilla */
+
+// SegmentCedilla is a cedilla hanging below the letter
+type SegmentCedilla struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentCedilla) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := seg.Width(), seg.Height()
+	centre := width/2 - 1
+	gc.MoveTo(centre-style.AccentThickness/2, height-1)
+	gc.LineTo(centre+style.AccentThickness/2, height-1)
+	gc.LineTo(centre+style.AccentThickness/2, height-1+style.CedillaDrop)
+	gc.LineTo(centre-style.AccentThickness/2-style.CedillaTail, height-1+style.CedillaDrop)
+	gc.LineTo(centre-style.AccentThickness/2-style.CedillaTail, height-1+style.CedillaDrop-style.AccentThickness/2)
+	gc.LineTo(centre-style.AccentThickness/2, height-1+style.CedillaDrop-style.AccentThickness/2)
+	gc.LineTo(centre-style.AccentThickness/2, height-1)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// extent returns how far the segment reaches outside of the cell
+func (seg *SegmentCedilla) extent(style *Style) (above, below float64) {
+	return 0, style.CedillaDrop
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentCedilla) ID() SegmentID { return IDCedilla }
+
+// DiacriticCedilla returns the segments for a cedilla
+func DiacriticCedilla() Letter {
+	return Letter{&SegmentCedilla{}}
+}
@@ -0,0 +1,92 @@
+package letters
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+func TestAccentedLetters(t *testing.T) {
+	tests := []struct {
+		char rune
+		base rune
+		// below is whether the diacritic hangs below the letter
+		below bool
+	}{
+		{'À', 'A', false},
+		{'Á', 'A', false},
+		{'Â', 'A', false},
+		{'Ã', 'A', false},
+		{'Ä', 'A', false},
+		{'Å', 'A', false},
+		{'Ç', 'C', true},
+		{'É', 'E', false},
+		{'Ñ', 'N', false},
+		{'Ü', 'U', false},
+		{'Ý', 'Y', false},
+	}
+	style := DefaultStyle
+	for _, test := range tests {
+		letterFunc, ok := DefaultFont.LookupLetter(test.char)
+		if !ok {
+			t.Errorf("'%c' is not registered", test.char)
+			continue
+		}
+		baseFunc, _ := DefaultFont.LookupLetter(test.base)
+		img, cellRect := drawGlyph(letterFunc(), DefaultFont, style)
+		baseImg, _ := drawGlyph(baseFunc(), DefaultFont, style)
+
+		// the diacritic is drawn in the space given to it above or
+		// below the cell, leaving the base letter untouched. A
+		// cedilla joins the bottom of the letter, so the edge it
+		// joins along isn't compared.
+		base := cellRect
+		allowed := image.Rect(cellRect.Min.X, cellRect.Min.Y-int((style.AccentGap+style.AccentHeight+1)*glyphScale), cellRect.Max.X, cellRect.Min.Y)
+		if test.below {
+			base.Max.Y -= glyphScale
+			allowed = image.Rect(cellRect.Min.X, cellRect.Max.Y, cellRect.Max.X, cellRect.Max.Y+int((style.CedillaDrop+1)*glyphScale))
+		}
+		outside, stray := 0, 0
+		for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+			for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+				p := image.Point{x, y}
+				if p.In(cellRect) {
+					if p.In(base) && img.RGBAAt(x, y) != baseImg.RGBAAt(x, y) {
+						t.Errorf("'%c' is not drawn the same as '%c' at (%d, %d)", test.char, test.base, x, y)
+						return
+					}
+					continue
+				}
+				if img.RGBAAt(x, y).A == 0 {
+					continue
+				}
+				if p.In(allowed) {
+					outside++
+				} else {
+					stray++
+				}
+			}
+		}
+		if outside == 0 {
+			t.Errorf("'%c' has no diacritic", test.char)
+		}
+		if stray != 0 {
+			t.Errorf("'%c' draws %d pixels outside of the space for its diacritic", test.char, stray)
+		}
+	}
+}
+
+func TestLettersRunes(t *testing.T) {
+	// runes outside of ASCII are looked up whole, not byte by byte
+	letters, _, err := DefaultFont.Letters("ÉTÉ", FallbackError)
+	if err != nil {
+		t.Fatalf("Letters returned error: %s", err)
+	}
+	want := []Letter{Accented(LetterE, DiacriticAcute)(), LetterT(), Accented(LetterE, DiacriticAcute)()}
+	if got, want := segmentIDs(letters), segmentIDs(want); !reflect.DeepEqual(got, want) {
+		t.Errorf("ÉTÉ has segments %v, want %v", got, want)
+	}
+	if _, ok := GetLetterMap()['Ñ']; !ok {
+		t.Error("GetLetterMap has no letter for 'Ñ'")
+	}
+}
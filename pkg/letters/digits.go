@@ -0,0 +1,226 @@
+package letters
+
+/* Digit 0 */
+
+// SegmentZeroRing is the ring making up a 0. Its corners are rounded
+// the same as the curve of a D.
+type SegmentZeroRing struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentZeroRing) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := seg.Width(), seg.Height()
+	// outside, clockwise
+	gc.MoveTo(style.StickThickness, 0)
+	gc.LineTo(width-style.StickThickness-1, 0)
+	ellipseCornerTo(gc, width-style.StickThickness-1, 0, width-1, 0, width-1, style.DDiagonalHeight-1)
+	gc.LineTo(width-1, height-style.DDiagonalHeight-1)
+	ellipseCornerTo(gc, width-1, height-style.DDiagonalHeight-1, width-1, height-1, width-style.StickThickness-1, height-1)
+	gc.LineTo(style.StickThickness, height-1)
+	ellipseCornerTo(gc, style.StickThickness, height-1, 0, height-1, 0, height-style.DDiagonalHeight-1)
+	gc.LineTo(0, style.DDiagonalHeight-1)
+	ellipseCornerTo(gc, 0, style.DDiagonalHeight-1, 0, 0, style.StickThickness, 0)
+	// inside, anticlockwise
+	gc.MoveTo(style.StickThickness, style.StickThickness-1)
+	gc.LineTo(style.StickThickness, height-style.StickThickness-1)
+	gc.LineTo(width-style.StickThickness-1, height-style.StickThickness-1)
+	gc.LineTo(width-style.StickThickness-1, style.StickThickness-1)
+	gc.LineTo(style.StickThickness, style.StickThickness-1)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentZeroRing) ID() SegmentID { return IDZeroRing }
+
+// LetterZero returns all the segments for the digit 0
+func LetterZero() Letter {
+	return Letter{&SegmentZeroRing{}}
+}
+
+/* Digit 1 */
+
+// SegmentOneFlag is the short stroke hanging off the top of a 1
+type SegmentOneFlag struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentOneFlag) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width := seg.Width()
+	gc.MoveTo(width/2-1-style.StickThickness/2, 0)
+	gc.LineTo(width/2-1-style.StickThickness/2, style.StickThickness-1)
+	gc.LineTo(width/2-1-style.StickThickness/2-style.OneFlagLength, style.StickThickness-1+style.OneFlagDrop)
+	gc.LineTo(width/2-1-style.StickThickness/2-style.OneFlagLength, style.OneFlagDrop)
+	gc.LineTo(width/2-1-style.StickThickness/2, 0)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentOneFlag) ID() SegmentID { return IDOneFlag }
+
+// LetterOne returns all the segments for the digit 1
+func LetterOne() Letter {
+	return Letter{&SegmentIMiddle{}, &SegmentOneFlag{}, &SegmentIBottom{}}
+}
+
+/* Digit 2 */
+
+// SegmentTwoDiagonal is the diagonal running from the top right to
+// the bottom left of a 2
+type SegmentTwoDiagonal struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentTwoDiagonal) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := seg.Width(), seg.Height()
+	gc.MoveTo(width-1-style.StickThickness, style.StickThickness+style.SStickLength-1)
+	gc.LineTo(width-1, style.StickThickness+style.SStickLength-1)
+	gc.LineTo(style.StickThickness, height-1-style.StickThickness)
+	gc.LineTo(0, height-1-style.StickThickness)
+	gc.LineTo(width-1-style.StickThickness, style.StickThickness+style.SStickLength-1)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentTwoDiagonal) ID() SegmentID { return IDTwoDiagonal }
+
+// LetterTwo returns all the segments for the digit 2
+func LetterTwo() Letter {
+	return Letter{&SegmentSUpperBar{}, &SegmentTwoDiagonal{}, &SegmentELower{}}
+}
+
+/* Digit 3 */
+
+// LetterThree returns all the segments for the digit 3
+func LetterThree() Letter {
+	return Letter{&SegmentNBar{}, &SegmentHBar{}, &SegmentNRightVert{}, &SegmentELower{}}
+}
+
+/* Digit 4 */
+
+// SegmentDigitUpperLeft is the vertical line on the top half of the
+// left hand side of a digit
+type SegmentDigitUpperLeft struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentDigitUpperLeft) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	height := seg.Height()
+	gc.MoveTo(0, 0)
+	gc.LineTo(style.StickThickness, 0)
+	gc.LineTo(style.StickThickness, height/2+style.StickThickness/2-1)
+	gc.LineTo(0, height/2+style.StickThickness/2-1)
+	gc.LineTo(0, 0)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentDigitUpperLeft) ID() SegmentID { return IDDigitUpperLeft }
+
+// LetterFour returns all the segments for the digit 4
+func LetterFour() Letter {
+	return Letter{&SegmentDigitUpperLeft{}, &SegmentHBar{}, &SegmentNRightVert{}}
+}
+
+/* Digit 5 */
+
+// SegmentDigitLowerRight is the vertical line on the bottom half of
+// the right hand side of a digit
+type SegmentDigitLowerRight struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentDigitLowerRight) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := seg.Width(), seg.Height()
+	gc.MoveTo(width-style.StickThickness-1, height/2-style.StickThickness/2-1)
+	gc.LineTo(width-1, height/2-style.StickThickness/2-1)
+	gc.LineTo(width-1, height-1)
+	gc.LineTo(width-style.StickThickness-1, height-1)
+	gc.LineTo(width-style.StickThickness-1, height/2-style.StickThickness/2-1)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentDigitLowerRight) ID() SegmentID { return IDDigitLowerRight }
+
+// LetterFive returns all the segments for the digit 5
+func LetterFive() Letter {
+	return Letter{&SegmentNBar{}, &SegmentDigitUpperLeft{}, &SegmentHBar{}, &SegmentDigitLowerRight{}, &SegmentELower{}}
+}
+
+/* Digit 6 */
+
+// LetterSix returns all the segments for the digit 6
+func LetterSix() Letter {
+	return Letter{&SegmentNLeftVert{}, &SegmentNBar{}, &SegmentHBar{}, &SegmentDigitLowerRight{}, &SegmentELower{}}
+}
+
+/* Digit 7 */
+
+// SegmentSevenDiagonal is the leg of a 7. It is the same as the
+// diagonal of a Z, but reaches the bottom of the cell as there is no
+// bar for it to meet.
+type SegmentSevenDiagonal struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentSevenDiagonal) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := seg.Width(), seg.Height()
+	gc.MoveTo(width-1-style.StickThickness, style.StickThickness-1)
+	gc.LineTo(width-1, style.StickThickness-1)
+	gc.LineTo(style.StickThickness, height-1)
+	gc.LineTo(0, height-1)
+	gc.LineTo(width-1-style.StickThickness, style.StickThickness-1)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentSevenDiagonal) ID() SegmentID { return IDSevenDiagonal }
+
+// LetterSeven returns all the segments for the digit 7
+func LetterSeven() Letter {
+	return Letter{&SegmentNBar{}, &SegmentSevenDiagonal{}}
+}
+
+/* Digit 8 */
+
+// LetterEight returns all the segments for the digit 8
+func LetterEight() Letter {
+	return Letter{&SegmentNLeftVert{}, &SegmentNBar{}, &SegmentHBar{}, &SegmentNRightVert{}, &SegmentELower{}}
+}
+
+/* Digit 9 */
+
+// LetterNine returns all the segments for the digit 9
+func LetterNine() Letter {
+	return Letter{&SegmentNBar{}, &SegmentDigitUpperLeft{}, &SegmentHBar{}, &SegmentNRightVert{}, &SegmentELower{}}
+}
@@ -0,0 +1,74 @@
+package letters
+
+import "math"
+
+// boundsMargin is added around the area a cell draws in, to cover
+// the antialiasing at the edges of its segments
+const boundsMargin = 2
+
+// drawState records how a cell was last drawn
+type drawState struct {
+	drawn         bool
+	topLeft       [2]float64
+	bottomRight   [2]float64
+	morphProgress float64
+}
+
+// Bounds returns the top left and bottom right of the area the cell
+// may draw in. This is larger than the cell itself when its segments
+// reach outside of it, as diacritics do above and below it.
+func (cell *Cell) Bounds() (topLeft, bottomRight [2]float64) {
+	segments := cell.Segments
+	if cell.morph != nil {
+		segments = []Segment{cell.morph}
+	}
+	above, below := 0.0, 0.0
+	for _, seg := range segments {
+		e, ok := seg.(extender)
+		if !ok {
+			continue
+		}
+		segAbove, segBelow := e.extent(&cell.Style)
+		scale := cell.Height() / seg.Height()
+		above = math.Max(above, segAbove*scale)
+		below = math.Max(below, segBelow*scale)
+	}
+	topLeft = [2]float64{cell.TopLeft[0] - boundsMargin, cell.TopLeft[1] - above - boundsMargin}
+	bottomRight = [2]float64{cell.BottomRight[0] + boundsMargin, cell.BottomRight[1] + below + boundsMargin}
+	return
+}
+
+// LastBounds returns the Bounds of the cell when it was last drawn,
+// and whether it has been drawn
+func (cell *Cell) LastBounds() (topLeft, bottomRight [2]float64, drawn bool) {
+	return cell.last.topLeft, cell.last.bottomRight, cell.last.drawn
+}
+
+// Dirty returns whether the cell needs drawing again, because it is
+// animating or has been changed since it was last drawn
+func (cell *Cell) Dirty() bool {
+	if cell.dirty || !cell.last.drawn {
+		return true
+	}
+	topLeft, bottomRight := cell.Bounds()
+	if topLeft != cell.last.topLeft || bottomRight != cell.last.bottomRight {
+		return true
+	}
+	return cell.morph != nil && cell.MorphProgress != cell.last.morphProgress
+}
+
+// Invalidate marks the cell as needing drawing again. This is only
+// needed after changing the cell's fields directly, as its methods
+// do this themselves.
+func (cell *Cell) Invalidate() {
+	cell.dirty = true
+}
+
+// drawn records that the cell has been drawn, and whether it is
+// still changing
+func (cell *Cell) drawn(changing bool) {
+	cell.dirty = changing
+	cell.last.drawn = true
+	cell.last.topLeft, cell.last.bottomRight = cell.Bounds()
+	cell.last.morphProgress = cell.MorphProgress
+}
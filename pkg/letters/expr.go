@@ -0,0 +1,250 @@
+package letters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// exprVars holds what the variables available to an expression are
+// read from
+type exprVars struct {
+	width, height float64
+	style         *Style
+}
+
+// expr is a compiled arithmetic expression over the style parameters
+// and the segment size
+type expr interface {
+	eval(vars exprVars) float64
+}
+
+type exprNumber float64
+
+func (e exprNumber) eval(vars exprVars) float64 { return float64(e) }
+
+// exprVariable reads a variable, which is looked up by name when the
+// expression is compiled rather than every time it is evaluated
+type exprVariable func(vars exprVars) float64
+
+func (e exprVariable) eval(vars exprVars) float64 { return e(vars) }
+
+type exprNegate struct {
+	operand expr
+}
+
+func (e exprNegate) eval(vars exprVars) float64 { return -e.operand.eval(vars) }
+
+type exprBinary struct {
+	op          byte
+	left, right expr
+}
+
+func (e exprBinary) eval(vars exprVars) float64 {
+	left, right := e.left.eval(vars), e.right.eval(vars)
+	switch e.op {
+	case '+':
+		return left + right
+	case '-':
+		return left - right
+	case '*':
+		return left * right
+	default:
+		return left / right
+	}
+}
+
+// styleVars are the Style fields that can be used in expressions, in
+// the order they are listed in errors
+var styleVars = []struct {
+	name  string
+	value func(style *Style) float64
+}{
+	{"StickThickness", func(style *Style) float64 { return style.StickThickness }},
+	{"SStickLength", func(style *Style) float64 { return style.SStickLength }},
+	{"ABarTopFromBottom", func(style *Style) float64 { return style.ABarTopFromBottom }},
+	{"EPointDepth", func(style *Style) float64 { return style.EPointDepth }},
+	{"EEdgeDepth", func(style *Style) float64 { return style.EEdgeDepth }},
+	{"IOverhang", func(style *Style) float64 { return style.IOverhang }},
+	{"DGapThinnest", func(style *Style) float64 { return style.DGapThinnest }},
+	{"DDiagonalHeight", func(style *Style) float64 { return style.DDiagonalHeight }},
+	{"BChamfer", func(style *Style) float64 { return style.BChamfer }},
+	{"GSpurDepth", func(style *Style) float64 { return style.GSpurDepth }},
+	{"MVertexHeight", func(style *Style) float64 { return style.MVertexHeight }},
+	{"WVertexHeight", func(style *Style) float64 { return style.WVertexHeight }},
+	{"OneFlagLength", func(style *Style) float64 { return style.OneFlagLength }},
+	{"OneFlagDrop", func(style *Style) float64 { return style.OneFlagDrop }},
+	{"PunctuationDotGap", func(style *Style) float64 { return style.PunctuationDotGap }},
+	{"CommaTail", func(style *Style) float64 { return style.CommaTail }},
+	{"ApostropheLength", func(style *Style) float64 { return style.ApostropheLength }},
+	{"HyphenWidth", func(style *Style) float64 { return style.HyphenWidth }},
+	{"SlashWidth", func(style *Style) float64 { return style.SlashWidth }},
+	{"QuestionWidth", func(style *Style) float64 { return style.QuestionWidth }},
+	{"AmpersandWidth", func(style *Style) float64 { return style.AmpersandWidth }},
+	{"AmpersandInset", func(style *Style) float64 { return style.AmpersandInset }},
+	{"HashWidth", func(style *Style) float64 { return style.HashWidth }},
+	{"HashInset", func(style *Style) float64 { return style.HashInset }},
+	{"ColonDotSeparation", func(style *Style) float64 { return style.ColonDotSeparation }},
+	{"AccentGap", func(style *Style) float64 { return style.AccentGap }},
+	{"AccentHeight", func(style *Style) float64 { return style.AccentHeight }},
+	{"AccentThickness", func(style *Style) float64 { return style.AccentThickness }},
+	{"AccentSlant", func(style *Style) float64 { return style.AccentSlant }},
+	{"CircumflexWidth", func(style *Style) float64 { return style.CircumflexWidth }},
+	{"DiaeresisGap", func(style *Style) float64 { return style.DiaeresisGap }},
+	{"TildeWidth", func(style *Style) float64 { return style.TildeWidth }},
+	{"RingSize", func(style *Style) float64 { return style.RingSize }},
+	{"RingThickness", func(style *Style) float64 { return style.RingThickness }},
+	{"CedillaDrop", func(style *Style) float64 { return style.CedillaDrop }},
+	{"CedillaTail", func(style *Style) float64 { return style.CedillaTail }},
+}
+
+// newExprVars returns the variables available to an expression when
+// drawing a segment of the given size with the given style
+func newExprVars(width, height float64, style *Style) exprVars {
+	return exprVars{width, height, style}
+}
+
+// lookupExprVar returns the variable with the given name, or false if
+// there isn't one
+func lookupExprVar(name string) (exprVariable, bool) {
+	switch name {
+	case "width":
+		return func(vars exprVars) float64 { return vars.width }, true
+	case "height":
+		return func(vars exprVars) float64 { return vars.height }, true
+	}
+	for _, styleVar := range styleVars {
+		if styleVar.name == name {
+			value := styleVar.value
+			return func(vars exprVars) float64 { return value(vars.style) }, true
+		}
+	}
+	return nil, false
+}
+
+// exprParser is a recursive descent parser for the expression
+// grammar:
+//
+//  expr   = term {("+" | "-") term}
+//  term   = factor {("*" | "/") factor}
+//  factor = "-" factor | "(" expr ")" | number | name
+type exprParser struct {
+	src string
+	pos int
+}
+
+// parseExpr compiles an expression
+func parseExpr(src string) (expr, error) {
+	p := &exprParser{src: src}
+	e, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos != len(p.src) {
+		return nil, fmt.Errorf("unexpected '%c' at position %d in expression '%s'", p.src[p.pos], p.pos, src)
+	}
+	return e, nil
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *exprParser) parseSum() (expr, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) || (p.src[p.pos] != '+' && p.src[p.pos] != '-') {
+			return left, nil
+		}
+		op := p.src[p.pos]
+		p.pos++
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = exprBinary{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseTerm() (expr, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) || (p.src[p.pos] != '*' && p.src[p.pos] != '/') {
+			return left, nil
+		}
+		op := p.src[p.pos]
+		p.pos++
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = exprBinary{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseFactor() (expr, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, fmt.Errorf("unexpected end of expression '%s'", p.src)
+	}
+	c := p.src[p.pos]
+	switch {
+	case c == '-':
+		p.pos++
+		operand, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return exprNegate{operand}, nil
+	case c == '(':
+		p.pos++
+		e, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.src) || p.src[p.pos] != ')' {
+			return nil, fmt.Errorf("missing ')' in expression '%s'", p.src)
+		}
+		p.pos++
+		return e, nil
+	case c == '.' || (c >= '0' && c <= '9'):
+		start := p.pos
+		for p.pos < len(p.src) && (p.src[p.pos] == '.' || (p.src[p.pos] >= '0' && p.src[p.pos] <= '9')) {
+			p.pos++
+		}
+		value, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad number in expression '%s': %w", p.src, err)
+		}
+		return exprNumber(value), nil
+	case unicode.IsLetter(rune(c)):
+		start := p.pos
+		for p.pos < len(p.src) && (unicode.IsLetter(rune(p.src[p.pos])) || unicode.IsDigit(rune(p.src[p.pos]))) {
+			p.pos++
+		}
+		name := p.src[start:p.pos]
+		variable, ok := lookupExprVar(name)
+		if !ok {
+			names := make([]string, len(styleVars))
+			for i, styleVar := range styleVars {
+				names[i] = styleVar.name
+			}
+			return nil, fmt.Errorf("unknown variable '%s' in expression '%s', expected width, height or one of %s", name, p.src, strings.Join(names, ", "))
+		}
+		return variable, nil
+	}
+	return nil, fmt.Errorf("unexpected '%c' at position %d in expression '%s'", c, p.pos, p.src)
+}
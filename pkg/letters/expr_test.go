@@ -0,0 +1,98 @@
+package letters
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseExpr(t *testing.T) {
+	style := DefaultStyle
+	vars := newExprVars(100, 200, &style)
+	tests := []struct {
+		src  string
+		want float64
+	}{
+		{"4", 4},
+		{"2.5", 2.5},
+		{"1+2*3", 7},
+		{"(1+2)*3", 9},
+		{"10-4-3", 3},
+		{"12/3/2", 2},
+		{"10-6/2", 7},
+		{"-3", -3},
+		{"--3", 3},
+		{"-3*-2", 6},
+		{"2--3", 5},
+		{"-(1+2)*4", -12},
+		{" width / 2 - 1 ", 49},
+		{"height-1", 199},
+		{"width/2-1-StickThickness/2", 49 - DefaultStyle.StickThickness/2},
+	}
+	for _, test := range tests {
+		e, err := parseExpr(test.src)
+		if err != nil {
+			t.Errorf("parseExpr(%q) returned error: %s", test.src, err)
+			continue
+		}
+		if got := e.eval(vars); got != test.want {
+			t.Errorf("parseExpr(%q) = %v, want %v", test.src, got, test.want)
+		}
+	}
+}
+
+func TestParseExprErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"", "unexpected end"},
+		{"1+", "unexpected end"},
+		{"-", "unexpected end"},
+		{"depth", "unknown variable 'depth'"},
+		{"Width", "unknown variable 'Width'"},
+		{"width*stickThickness", "unknown variable 'stickThickness'"},
+		{"1 2", "unexpected '2' at position 2"},
+		{"width)", "unexpected ')' at position 5"},
+		{"(1+2", "missing ')'"},
+		{"1..2", "bad number"},
+		{"2^3", "unexpected '^' at position 1"},
+		{"*2", "unexpected '*' at position 0"},
+	}
+	for _, test := range tests {
+		_, err := parseExpr(test.src)
+		if err == nil {
+			t.Errorf("parseExpr(%q) returned no error", test.src)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.want) {
+			t.Errorf("parseExpr(%q) returned error %q, want one containing %q", test.src, err, test.want)
+		}
+	}
+}
+
+// TestStyleVars checks that every field of Style can be used in an
+// expression, and reads the right field
+func TestStyleVars(t *testing.T) {
+	style := Style{}
+	styleValue := reflect.ValueOf(&style).Elem()
+	styleType := styleValue.Type()
+	if len(styleVars) != styleType.NumField() {
+		t.Errorf("there are %d style variables for %d Style fields", len(styleVars), styleType.NumField())
+	}
+	for i := 0; i < styleType.NumField(); i++ {
+		styleValue.Field(i).SetFloat(float64(i + 1))
+	}
+	vars := newExprVars(0, 0, &style)
+	for i := 0; i < styleType.NumField(); i++ {
+		name := styleType.Field(i).Name
+		e, err := parseExpr(name)
+		if err != nil {
+			t.Errorf("parseExpr(%q) returned error: %s", name, err)
+			continue
+		}
+		if got := e.eval(vars); got != float64(i+1) {
+			t.Errorf("%s evaluates to %v, want %v", name, got, float64(i+1))
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package letters
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrLetterUnavailable is returned when a font has no letter for a
+// character and the fallback policy doesn't allow it to be replaced
+var ErrLetterUnavailable = errors.New("letter not available")
+
+// TofuRune is the rune whose letter is drawn in place of unavailable
+// characters by FallbackTofu
+const TofuRune = '\uFFFD'
+
+// FallbackPolicy decides what happens to characters that a font
+// can't draw
+type FallbackPolicy int
+
+// Possible fallback policies
+const (
+	// FallbackError returns an error for the whole phrase
+	FallbackError FallbackPolicy = iota
+	// FallbackSkip leaves the character out
+	FallbackSkip
+	// FallbackTofu draws a box in place of the character
+	FallbackTofu
+	// FallbackDecompose replaces the character with its
+	// compatibility decomposition (NFKD) without any combining marks,
+	// upper casing it if needed, so 'é' becomes 'E'. If that still
+	// can't be drawn, a box is drawn as with FallbackTofu.
+	FallbackDecompose
+)
+
+var fallbackPolicyNames = []string{"error", "skip", "tofu", "decompose"}
+
+// String returns the name of the policy
+func (policy FallbackPolicy) String() string {
+	if policy < 0 || int(policy) >= len(fallbackPolicyNames) {
+		return fmt.Sprintf("FallbackPolicy(%d)", int(policy))
+	}
+	return fallbackPolicyNames[policy]
+}
+
+// ParseFallbackPolicy returns the policy with the given name
+func ParseFallbackPolicy(name string) (FallbackPolicy, error) {
+	for i, policyName := range fallbackPolicyNames {
+		if strings.EqualFold(name, policyName) {
+			return FallbackPolicy(i), nil
+		}
+	}
+	return FallbackError, fmt.Errorf("unknown fallback policy '%s', expected one of %s", name, strings.Join(fallbackPolicyNames, ", "))
+}
+
+// Substitution records a character that was not drawn as itself
+type Substitution struct {
+	// Index is the position of the character in the text, counted
+	// in runes
+	Index    int
+	Original rune
+	// Replacement holds the runes drawn instead, which is empty if
+	// the character was skipped
+	Replacement []rune
+}
+
+// Letters returns the letters for each of the characters in the
+// text. Characters the font has no letter for are handled according
+// to the policy, and each one that is replaced or skipped is
+// reported in the returned substitutions. An error wrapping
+// ErrFallbackCycle is returned if the font's fallbacks fall back to
+// each other.
+func (font *Font) Letters(text string, policy FallbackPolicy) ([]Letter, []Substitution, error) {
+	if _, err := font.Fallbacks(); err != nil {
+		return nil, nil, err
+	}
+	letters := []Letter{}
+	substitutions := []Substitution{}
+	index := 0
+	for _, char := range text {
+		if letterFunc, ok := font.LookupLetter(char); ok {
+			letters = append(letters, letterFunc())
+			index++
+			continue
+		}
+
+		var replacement []rune
+		switch policy {
+		case FallbackSkip:
+		case FallbackTofu:
+			replacement = []rune{TofuRune}
+		case FallbackDecompose:
+			replacement = font.decompose(char)
+			if replacement == nil {
+				replacement = []rune{TofuRune}
+			}
+		default:
+			return nil, nil, fmt.Errorf("character '%s' in font '%s': %w", string(char), font.Name, ErrLetterUnavailable)
+		}
+		for _, r := range replacement {
+			letters = append(letters, font.letterOrTofu(r))
+		}
+		substitutions = append(substitutions, Substitution{
+			Index:       index,
+			Original:    char,
+			Replacement: replacement,
+		})
+		index++
+	}
+	return letters, substitutions, nil
+}
+
+// decompose returns the runes of the compatibility decomposition of
+// a character with any combining marks removed, each upper cased if
+// the font doesn't have it as it is, or nil if the font can't draw
+// them
+func (font *Font) decompose(char rune) []rune {
+	decomposed := []rune{}
+	for _, r := range norm.NFKD.String(string(char)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if _, ok := font.LookupLetter(r); !ok {
+			r = unicode.ToUpper(r)
+			if _, ok := font.LookupLetter(r); !ok {
+				return nil
+			}
+		}
+		decomposed = append(decomposed, r)
+	}
+	if len(decomposed) == 0 {
+		return nil
+	}
+	return decomposed
+}
+
+// letterOrTofu returns the font's letter for a rune, or the tofu
+// letter if it has none
+func (font *Font) letterOrTofu(r rune) Letter {
+	if letterFunc, ok := font.LookupLetter(r); ok {
+		return letterFunc()
+	}
+	return LetterTofu()
+}
+
+/* Tofu */
+
+// SegmentTofu is the hollow box drawn in place of unavailable
+// characters
+type SegmentTofu struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentTofu) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := seg.Width(), seg.Height()
+	thickness := style.StickThickness / 3
+	// outside, clockwise
+	gc.MoveTo(0, 0)
+	gc.LineTo(width-1, 0)
+	gc.LineTo(width-1, height-1)
+	gc.LineTo(0, height-1)
+	gc.LineTo(0, 0)
+	// inside, anticlockwise
+	gc.MoveTo(thickness, thickness)
+	gc.LineTo(thickness, height-1-thickness)
+	gc.LineTo(width-1-thickness, height-1-thickness)
+	gc.LineTo(width-1-thickness, thickness)
+	gc.LineTo(thickness, thickness)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentTofu) ID() SegmentID { return IDTofu }
+
+// LetterTofu returns all the segments for the box drawn in place of
+// unavailable characters
+func LetterTofu() Letter {
+	return Letter{&SegmentTofu{}}
+}
@@ -0,0 +1,129 @@
+package letters
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// segmentIDs returns the IDs of the segments of each letter, for
+// comparing letters
+func segmentIDs(letters []Letter) [][]SegmentID {
+	ids := make([][]SegmentID, len(letters))
+	for i, letter := range letters {
+		ids[i] = []SegmentID{}
+		for _, seg := range letter {
+			ids[i] = append(ids[i], seg.ID())
+		}
+	}
+	return ids
+}
+
+// fontLetters returns the letters of DefaultFont for each rune, or
+// the tofu letter for runes it doesn't have
+func fontLetters(runes ...rune) []Letter {
+	letters := []Letter{}
+	for _, r := range runes {
+		letters = append(letters, DefaultFont.letterOrTofu(r))
+	}
+	return letters
+}
+
+func TestLettersFallback(t *testing.T) {
+	// each of the characters the font doesn't have follows one it
+	// does, so that the indices of the substitutions are checked
+	const text = "AéBŝCﬁD☃"
+	tests := []struct {
+		policy        FallbackPolicy
+		letters       []Letter
+		substitutions []Substitution
+	}{
+		{
+			policy:  FallbackSkip,
+			letters: fontLetters('A', 'B', 'C', 'D'),
+			substitutions: []Substitution{
+				{Index: 1, Original: 'é'},
+				{Index: 3, Original: 'ŝ'},
+				{Index: 5, Original: 'ﬁ'},
+				{Index: 7, Original: '☃'},
+			},
+		},
+		{
+			policy:  FallbackTofu,
+			letters: fontLetters('A', TofuRune, 'B', TofuRune, 'C', TofuRune, 'D', TofuRune),
+			substitutions: []Substitution{
+				{Index: 1, Original: 'é', Replacement: []rune{TofuRune}},
+				{Index: 3, Original: 'ŝ', Replacement: []rune{TofuRune}},
+				{Index: 5, Original: 'ﬁ', Replacement: []rune{TofuRune}},
+				{Index: 7, Original: '☃', Replacement: []rune{TofuRune}},
+			},
+		},
+		{
+			// é and ŝ lose their accents and are upper cased, even
+			// though the font has É, and ﬁ is split into two letters
+			policy:  FallbackDecompose,
+			letters: fontLetters('A', 'E', 'B', 'S', 'C', 'F', 'I', 'D', TofuRune),
+			substitutions: []Substitution{
+				{Index: 1, Original: 'é', Replacement: []rune{'E'}},
+				{Index: 3, Original: 'ŝ', Replacement: []rune{'S'}},
+				{Index: 5, Original: 'ﬁ', Replacement: []rune{'F', 'I'}},
+				{Index: 7, Original: '☃', Replacement: []rune{TofuRune}},
+			},
+		},
+	}
+	for _, test := range tests {
+		letters, substitutions, err := DefaultFont.Letters(text, test.policy)
+		if err != nil {
+			t.Errorf("%s: Letters returned error: %s", test.policy, err)
+			continue
+		}
+		if got, want := segmentIDs(letters), segmentIDs(test.letters); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: Letters returned letters with segments %v, want %v", test.policy, got, want)
+		}
+		if !reflect.DeepEqual(substitutions, test.substitutions) {
+			t.Errorf("%s: Letters returned substitutions %+v, want %+v", test.policy, substitutions, test.substitutions)
+		}
+	}
+}
+
+func TestLettersFallbackError(t *testing.T) {
+	for _, char := range []string{"é", "ŝ", "ﬁ", "☃"} {
+		_, _, err := DefaultFont.Letters("A"+char, FallbackError)
+		if !errors.Is(err, ErrLetterUnavailable) {
+			t.Errorf("Letters(%q) returned error %v, want %v", "A"+char, err, ErrLetterUnavailable)
+		}
+	}
+	letters, substitutions, err := DefaultFont.Letters("AÉ", FallbackError)
+	if err != nil {
+		t.Fatalf("Letters(%q) returned error: %s", "AÉ", err)
+	}
+	if len(letters) != 2 || len(substitutions) != 0 {
+		t.Errorf("Letters(%q) returned %d letters and %d substitutions, want 2 and 0", "AÉ", len(letters), len(substitutions))
+	}
+}
+
+func TestDecompose(t *testing.T) {
+	tests := []struct {
+		char rune
+		want []rune
+	}{
+		// the accents are dropped, even when the font has the
+		// accented letter in upper case
+		{'é', []rune{'E'}},
+		{'ñ', []rune{'N'}},
+		{'ŝ', []rune{'S'}},
+		{'Ŝ', []rune{'S'}},
+		{'ﬁ', []rune{'F', 'I'}},
+		{'²', []rune{'2'}},
+		// characters without a decomposition are upper cased
+		{'a', []rune{'A'}},
+		// characters that can't be drawn at all
+		{'☃', nil},
+		{'\u0301', nil},
+	}
+	for _, test := range tests {
+		if got := DefaultFont.decompose(test.char); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("decompose(%q) = %q, want %q", test.char, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package letters
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Errors returned when registering fonts
+var (
+	ErrFontRegistered    = errors.New("font already registered")
+	ErrFontNotRegistered = errors.New("font not registered")
+)
+
+// Font bundles a set of letters with the metrics and style used to
+// draw them. Width and Height are the size of the cell each letter is
+// designed on, and Style is given to each cell created with the font.
+// A Font can be created directly as well as with NewFont, and starts
+// with no letters of its own.
+type Font struct {
+	Name   string
+	Width  float64
+	Height float64
+	Style  Style
+	// Fallback is searched for any letters the font doesn't have
+	Fallback *Font
+
+	lock   sync.RWMutex
+	glyphs map[rune]func() Letter
+}
+
+// NewFont creates a new Font with no letters of its own. Letters
+// can be added with RegisterLetter, and any missing letters are taken
+// from the fallback font if it is not nil.
+func NewFont(name string, width, height float64, style Style, fallback *Font) *Font {
+	return &Font{
+		Name:     name,
+		Width:    width,
+		Height:   height,
+		Style:    style,
+		Fallback: fallback,
+		glyphs:   make(map[rune]func() Letter),
+	}
+}
+
+// Built in fonts
+var (
+	// DefaultFont is the original font, and holds all of the
+	// built in letters
+	DefaultFont = &Font{
+		Name:   "default",
+		Width:  DefaultWidth,
+		Height: DefaultHeight,
+		Style:  DefaultStyle,
+		glyphs: letterMap,
+	}
+	// CondensedFont is a narrower version of DefaultFont with
+	// thinner strokes
+	CondensedFont = NewFont("condensed", 40, DefaultHeight, DefaultStyle.WithWeight(14), DefaultFont)
+	// HeavyFont is a wider version of DefaultFont with thicker
+	// strokes
+	HeavyFont = NewFont("heavy", 62, DefaultHeight, BlackStyle, DefaultFont)
+)
+
+var (
+	fontsLock sync.RWMutex
+	fonts     = map[string]*Font{
+		DefaultFont.Name:   DefaultFont,
+		CondensedFont.Name: CondensedFont,
+		HeavyFont.Name:     HeavyFont,
+	}
+)
+
+// RegisterFont adds a font to the registry of named fonts. An error
+// wrapping ErrFontRegistered is returned if a font with the same
+// name has already been registered, or wrapping ErrFallbackCycle if
+// its fallbacks fall back to each other.
+func RegisterFont(font *Font) error {
+	if _, err := font.Fallbacks(); err != nil {
+		return fmt.Errorf("could not register font '%s': %w", font.Name, err)
+	}
+	fontsLock.Lock()
+	defer fontsLock.Unlock()
+	if _, ok := fonts[font.Name]; ok {
+		return fmt.Errorf("could not register font '%s': %w", font.Name, ErrFontRegistered)
+	}
+	fonts[font.Name] = font
+	return nil
+}
+
+// UnregisterFont removes a font from the registry of named fonts
+func UnregisterFont(name string) error {
+	fontsLock.Lock()
+	defer fontsLock.Unlock()
+	if _, ok := fonts[name]; !ok {
+		return fmt.Errorf("could not unregister font '%s': %w", name, ErrFontNotRegistered)
+	}
+	delete(fonts, name)
+	return nil
+}
+
+// LookupFont returns the registered font with the given name, and
+// whether there was one
+func LookupFont(name string) (*Font, bool) {
+	fontsLock.RLock()
+	defer fontsLock.RUnlock()
+	font, ok := fonts[name]
+	return font, ok
+}
+
+// RegisteredFonts returns the names of all registered fonts, in
+// alphabetical order
+func RegisteredFonts() []string {
+	fontsLock.RLock()
+	names := make([]string, 0, len(fonts))
+	for name := range fonts {
+		names = append(names, name)
+	}
+	fontsLock.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// AdvanceWidth returns how much horizontal space a letter takes up
+// when drawn in this font and its style. This is the widest advance
+// width of any of its segments, or the font's width for a letter with
+// no segments.
+func (font *Font) AdvanceWidth(letter Letter) float64 {
+	return font.AdvanceWidthIn(letter, &font.Style)
+}
+
+// AdvanceWidthIn returns how much horizontal space a letter takes up
+// when drawn in this font with a different style, such as that of a
+// cell which has been given its own
+func (font *Font) AdvanceWidthIn(letter Letter, style *Style) float64 {
+	if len(letter) == 0 {
+		return font.Width
+	}
+	advance := 0.
+	for _, seg := range letter {
+		segAdvance := font.Width
+		if advancer, ok := seg.(Advancer); ok {
+			segAdvance = advancer.AdvanceWidth(font, style)
+		}
+		if segAdvance > advance {
+			advance = segAdvance
+		}
+	}
+	return advance
+}
@@ -0,0 +1,351 @@
+package letters
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"unicode/utf8"
+)
+
+// FirstUserSegmentID is the lowest ID that may be given to segments
+// loaded from a glyph definition file. IDs below this are reserved
+// for the built in segments.
+const FirstUserSegmentID SegmentID = 1 << 16
+
+// A glyph definition file is a JSON document describing segments as
+// filled paths, and letters as lists of those segments. Coordinates
+// are in the segment coordinate system, and are either numbers or
+// expressions using +, -, *, /, brackets, width, height and the names
+// of any of the fields of Style.
+//
+// Each path starts at a point, given as [x, y], and is joined by
+// straight lines to the points after it. A path can instead curve to
+// its next point with one of:
+//
+//  {"quad": [cx, cy, x, y]}
+//  {"cubic": [cx1, cy1, cx2, cy2, x, y]}
+//  {"arc": [cx, cy, rx, ry, startAngle, angle]}
+//
+// where the c coordinates are control points, and an arc is part of
+// the ellipse centred on (cx, cy), with the angles in degrees going
+// clockwise from the right. For example:
+//
+//  {
+//    "segments": {
+//      "TBar": {
+//        "id": 65536,
+//        "paths": [
+//          [[0, 0], ["width-1", 0], ["width-1", "StickThickness-1"], [0, "StickThickness-1"]]
+//        ]
+//      },
+//      "TStem": {
+//        "id": 65537,
+//        "paths": [
+//          [["width/2-1-StickThickness/2", 0], ["width/2-1+StickThickness/2", 0],
+//           ["width/2-1+StickThickness/2", "height-1"], ["width/2-1-StickThickness/2", "height-1"]]
+//        ]
+//      }
+//    },
+//    "letters": {
+//      "T": ["TBar", "TStem"]
+//    }
+//  }
+
+// glyphFile is the structure of a glyph definition file
+type glyphFile struct {
+	Segments map[string]glyphFileSegment `json:"segments"`
+	Letters  map[string][]string         `json:"letters"`
+}
+
+// glyphFileSegment is the structure of a segment in a glyph
+// definition file
+type glyphFileSegment struct {
+	ID    SegmentID          `json:"id"`
+	Paths [][]glyphFilePoint `json:"paths"`
+}
+
+// glyphFileOp is the way a path reaches one of its points
+type glyphFileOp int
+
+// Ways a path can reach a point
+const (
+	opLine glyphFileOp = iota
+	opQuad
+	opCubic
+	opArc
+)
+
+// glyphFileOpCoords is the number of coordinates each way of reaching
+// a point takes
+var glyphFileOpCoords = map[glyphFileOp]int{
+	opLine:  2,
+	opQuad:  4,
+	opCubic: 6,
+	opArc:   6,
+}
+
+// glyphFilePoint is a point of a path in a glyph definition file,
+// and the way the path reaches it
+type glyphFilePoint struct {
+	op     glyphFileOp
+	coords []glyphFileCoord
+}
+
+// glyphFileCurve is the structure of a point reached by a curve
+type glyphFileCurve struct {
+	Quad  []glyphFileCoord `json:"quad"`
+	Cubic []glyphFileCoord `json:"cubic"`
+	Arc   []glyphFileCoord `json:"arc"`
+}
+
+// UnmarshalJSON reads a point, which is either an array of
+// coordinates or a curve
+func (point *glyphFilePoint) UnmarshalJSON(data []byte) error {
+	if len(data) == 0 || data[0] != '{' {
+		point.op = opLine
+		return json.Unmarshal(data, &point.coords)
+	}
+	var curve glyphFileCurve
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&curve); err != nil {
+		return err
+	}
+	found := 0
+	for op, coords := range map[glyphFileOp][]glyphFileCoord{opQuad: curve.Quad, opCubic: curve.Cubic, opArc: curve.Arc} {
+		if coords != nil {
+			point.op, point.coords = op, coords
+			found++
+		}
+	}
+	if found != 1 {
+		return fmt.Errorf("curve must be exactly one of quad, cubic or arc, got %s", string(data))
+	}
+	return nil
+}
+
+// String returns the name of the way of reaching a point, as used in
+// error messages
+func (op glyphFileOp) String() string {
+	switch op {
+	case opQuad:
+		return "quad"
+	case opCubic:
+		return "cubic"
+	case opArc:
+		return "arc"
+	default:
+		return "point"
+	}
+}
+
+// eval returns the values of the point's coordinates
+func (point *glyphFilePoint) eval(vars exprVars) []float64 {
+	values := make([]float64, len(point.coords))
+	for i, coord := range point.coords {
+		values[i] = coord.eval(vars)
+	}
+	return values
+}
+
+// drawTo draws from the current point to the point
+func (point *glyphFilePoint) drawTo(gc CellDrawer, vars exprVars) {
+	v := point.eval(vars)
+	switch point.op {
+	case opQuad:
+		gc.QuadCurveTo(v[0], v[1], v[2], v[3])
+	case opCubic:
+		gc.CubicCurveTo(v[0], v[1], v[2], v[3], v[4], v[5])
+	case opArc:
+		gc.ArcTo(v[0], v[1], v[2], v[3], v[4]*math.Pi/180, v[5]*math.Pi/180)
+	default:
+		gc.LineTo(v[0], v[1])
+	}
+}
+
+// ys returns the heights that the path can reach on the way to the
+// point, which for curves include the control points
+func (point *glyphFilePoint) ys(vars exprVars) []float64 {
+	v := point.eval(vars)
+	switch point.op {
+	case opQuad:
+		return []float64{v[1], v[3]}
+	case opCubic:
+		return []float64{v[1], v[3], v[5]}
+	case opArc:
+		// the whole ellipse, which is never less than the arc
+		return []float64{v[1] - math.Abs(v[3]), v[1] + math.Abs(v[3])}
+	default:
+		return []float64{v[1]}
+	}
+}
+
+// glyphFileCoord is a coordinate in a glyph definition file, which
+// may be either a number or an expression
+type glyphFileCoord struct {
+	expr
+}
+
+// UnmarshalJSON compiles the coordinate
+func (coord *glyphFileCoord) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return fmt.Errorf("coordinate must be a number or an expression, got null")
+	}
+	var number float64
+	if err := json.Unmarshal(data, &number); err == nil {
+		coord.expr = exprNumber(number)
+		return nil
+	}
+	var src string
+	if err := json.Unmarshal(data, &src); err != nil {
+		return fmt.Errorf("coordinate must be a number or an expression, got %s", string(data))
+	}
+	e, err := parseExpr(src)
+	if err != nil {
+		return err
+	}
+	coord.expr = e
+	return nil
+}
+
+// PolygonSegment is a segment drawn as a set of filled paths, as
+// loaded from a glyph definition file
+type PolygonSegment struct {
+	segment
+	Name      string
+	SegmentID SegmentID
+	paths     [][]glyphFilePoint
+}
+
+// Draw defines the behaviour of the segment
+func (seg *PolygonSegment) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	vars := newExprVars(seg.Width(), seg.Height(), &cell.Style)
+	for _, path := range seg.paths {
+		start := path[0].eval(vars)
+		gc.MoveTo(start[0], start[1])
+		for _, point := range path[1:] {
+			point.drawTo(gc, vars)
+		}
+		gc.LineTo(start[0], start[1])
+	}
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// extent returns how far the paths reach outside of the cell
+func (seg *PolygonSegment) extent(style *Style) (above, below float64) {
+	vars := newExprVars(seg.Width(), seg.Height(), style)
+	for _, path := range seg.paths {
+		for _, point := range path {
+			for _, y := range point.ys(vars) {
+				above = math.Max(above, -y)
+				below = math.Max(below, y-(seg.Height()-1))
+			}
+		}
+	}
+	return
+}
+
+// ID returns the ID of the segment
+func (seg *PolygonSegment) ID() SegmentID { return seg.SegmentID }
+
+// ParseGlyphs reads a glyph definition file and returns a map of
+// runes to functions creating each of the letters it defines
+func ParseGlyphs(r io.Reader) (map[rune]func() Letter, error) {
+	var file glyphFile
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("could not parse glyph file: %w", err)
+	}
+
+	// sort the names so that errors are reported consistently
+	names := make([]string, 0, len(file.Segments))
+	for name := range file.Segments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	usedIDs := make(map[SegmentID]string)
+	for _, name := range names {
+		seg := file.Segments[name]
+		if seg.ID < FirstUserSegmentID {
+			return nil, fmt.Errorf("segment '%s' has ID %d, but IDs below %d are reserved", name, seg.ID, FirstUserSegmentID)
+		}
+		if other, ok := usedIDs[seg.ID]; ok {
+			return nil, fmt.Errorf("segments '%s' and '%s' have the same ID %d", other, name, seg.ID)
+		}
+		usedIDs[seg.ID] = name
+		if len(seg.Paths) == 0 {
+			return nil, fmt.Errorf("segment '%s' has no paths", name)
+		}
+		for i, path := range seg.Paths {
+			// a curve can enclose an area with only one other point
+			curved := false
+			for _, point := range path {
+				curved = curved || point.op != opLine
+			}
+			if len(path) < 2 || (len(path) < 3 && !curved) {
+				return nil, fmt.Errorf("path %d of segment '%s' has fewer than 3 points", i, name)
+			}
+			if path[0].op != opLine {
+				return nil, fmt.Errorf("path %d of segment '%s' starts with a %s, expected a point", i, name, path[0].op)
+			}
+			for j, point := range path {
+				if want := glyphFileOpCoords[point.op]; len(point.coords) != want {
+					return nil, fmt.Errorf("%s %d of path %d of segment '%s' has %d coordinates, expected %d", point.op, j, i, name, len(point.coords), want)
+				}
+			}
+		}
+	}
+
+	letterMap := make(map[rune]func() Letter)
+	for key, segNames := range file.Letters {
+		r, size := utf8.DecodeRuneInString(key)
+		if r == utf8.RuneError || size != len(key) {
+			return nil, fmt.Errorf("letter '%s' must be a single character", key)
+		}
+		for _, name := range segNames {
+			if _, ok := file.Segments[name]; !ok {
+				return nil, fmt.Errorf("letter '%s' uses undefined segment '%s'", key, name)
+			}
+		}
+		letterMap[r] = polygonLetter(segNames, file.Segments)
+	}
+	return letterMap, nil
+}
+
+// polygonLetter returns a function creating a letter from segments
+// in a glyph definition file
+func polygonLetter(names []string, segments map[string]glyphFileSegment) func() Letter {
+	return func() Letter {
+		letter := make(Letter, len(names))
+		for i, name := range names {
+			letter[i] = &PolygonSegment{
+				Name:      name,
+				SegmentID: segments[name].ID,
+				paths:     segments[name].Paths,
+			}
+		}
+		return letter
+	}
+}
+
+// LoadGlyphs reads a glyph definition file and adds all of its
+// letters to the font, replacing any the font already has
+func (font *Font) LoadGlyphs(r io.Reader) error {
+	letterMap, err := ParseGlyphs(r)
+	if err != nil {
+		return err
+	}
+	for char, f := range letterMap {
+		if _, err := font.ReplaceLetter(char, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
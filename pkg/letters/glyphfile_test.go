@@ -0,0 +1,106 @@
+package letters
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+)
+
+// glyphFileWithPath returns a glyph file with a single segment with
+// the given path, used by the letter A
+func glyphFileWithPath(path string) string {
+	return `{"segments": {"Test": {"id": 65536, "paths": [` + path + `]}}, "letters": {"A": ["Test"]}}`
+}
+
+func TestParseGlyphs(t *testing.T) {
+	letterMap, err := ParseGlyphs(strings.NewReader(glyphFileWithPath(`[[0, 0], ["width-1", 0], ["width-1", "height-1"]]`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	create, ok := letterMap['A']
+	if !ok {
+		t.Fatal("letter A is missing")
+	}
+	letter := create()
+	if len(letter) != 1 || letter[0].ID() != FirstUserSegmentID {
+		t.Errorf("letter A has segments %v, want one with ID %d", letter, FirstUserSegmentID)
+	}
+}
+
+func TestParseGlyphsCurves(t *testing.T) {
+	const path = `[[0, 0], {"quad": [10, 0, 10, 10]}, {"cubic": [10, 20, 0, 20, 0, 30]}, {"arc": [0, 40, 5, "height", 270, -180]}]`
+	letterMap, err := ParseGlyphs(strings.NewReader(glyphFileWithPath(path)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the segment is drawn on a cell the size of the font, so that
+	// the coordinates aren't changed
+	cell := NewCell([2]float64{0, 0}, [2]float64{DefaultWidth, DefaultHeight}, letterMap['A'](), ColorsDeath, ColorsParadox, DefaultFont)
+	paths := cell.Paths()
+	if len(paths) != 1 {
+		t.Fatalf("letter A has %d paths, want 1", len(paths))
+	}
+	wantComponents := []draw2d.PathCmp{
+		draw2d.MoveToCmp, draw2d.QuadCurveToCmp, draw2d.CubicCurveToCmp,
+		draw2d.LineToCmp, draw2d.ArcToCmp, draw2d.LineToCmp,
+	}
+	if got := paths[0].Path.Components; !reflect.DeepEqual(got, wantComponents) {
+		t.Errorf("path has components %v, want %v", got, wantComponents)
+	}
+	// the arc's angles are given in degrees
+	arc := paths[0].Path.Points[14:20]
+	wantArc := []float64{0, 40, 5, DefaultHeight, 3 * math.Pi / 2, -math.Pi}
+	for i := range arc {
+		if math.Abs(arc[i]-wantArc[i]) > 1e-9 {
+			t.Errorf("arc is %v, want %v", arc, wantArc)
+			break
+		}
+	}
+
+	// the ellipse of the arc reaches outside both the top and the
+	// bottom of the cell
+	above, below := letterMap['A']()[0].(*PolygonSegment).extent(&DefaultStyle)
+	if wantAbove, wantBelow := DefaultHeight-40, 40+DefaultHeight-(DefaultHeight-1); above != wantAbove || below != wantBelow {
+		t.Errorf("extent is %v above and %v below, want %v and %v", above, below, wantAbove, wantBelow)
+	}
+}
+
+func TestParseGlyphsMalformedPaths(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"too few points", `[[0, 0], [1, 1]]`, "fewer than 3 points"},
+		{"one coordinate", `[[0, 0], [5], [1, 1]]`, "point 1 of path 0 of segment 'Test' has 1 coordinates"},
+		{"three coordinates", `[[0, 0], [1, 0], [1, 1, 1]]`, "point 2 of path 0 of segment 'Test' has 3 coordinates"},
+		{"no coordinates", `[[0, 0], [], [1, 1]]`, "has 0 coordinates"},
+		{"null point", `[[0, 0], null, [1, 1]]`, "has 0 coordinates"},
+		{"null coordinate", `[[0, 0], [1, null], [1, 1]]`, "got null"},
+		{"null path", `null`, "fewer than 3 points"},
+		{"boolean coordinate", `[[0, 0], [1, true], [1, 1]]`, "got true"},
+		{"bad expression", `[[0, 0], ["width+", 0], [1, 1]]`, "unexpected end"},
+		{"unknown variable", `[[0, 0], ["depth", 0], [1, 1]]`, "unknown variable 'depth'"},
+		{"short quad", `[[0, 0], {"quad": [1, 1, 2]}]`, "quad 1 of path 0 of segment 'Test' has 3 coordinates, expected 4"},
+		{"short cubic", `[[0, 0], {"cubic": [1, 1, 2, 2]}]`, "cubic 1 of path 0 of segment 'Test' has 4 coordinates, expected 6"},
+		{"short arc", `[[0, 0], {"arc": [1, 1, 2, 2, 0]}]`, "arc 1 of path 0 of segment 'Test' has 5 coordinates, expected 6"},
+		{"curve first", `[{"quad": [1, 1, 2, 2]}, [0, 0], [1, 1]]`, "starts with a quad"},
+		{"curve alone", `[[0, 0]]`, "fewer than 3 points"},
+		{"two curves", `[[0, 0], {"quad": [1, 1, 2, 2], "arc": [1, 1, 2, 2, 0, 90]}]`, "exactly one of quad, cubic or arc"},
+		{"no curve", `[[0, 0], {}]`, "exactly one of quad, cubic or arc"},
+		{"unknown curve", `[[0, 0], {"spiral": [1, 1]}]`, "unknown field"},
+	}
+	for _, test := range tests {
+		_, err := ParseGlyphs(strings.NewReader(glyphFileWithPath(test.path)))
+		if err == nil {
+			t.Errorf("%s: ParseGlyphs returned no error", test.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: ParseGlyphs returned error %q, want one containing %q", test.name, err, test.want)
+		}
+	}
+}
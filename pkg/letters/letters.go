@@ -2,6 +2,7 @@ package letters
 
 import (
 	"image/color"
+	"math"
 )
 
 // Default dimensions of the original segment's cell
@@ -10,7 +11,7 @@ const (
 	DefaultHeight float64 = 85.
 )
 
-// Letter Thickness Guides
+// Letter Thickness Guides, used by DefaultStyle
 const (
 	stickThickness float64 = 18
 )
@@ -45,32 +46,153 @@ const (
 	IDITop
 	IDIBottom
 	IDDCurve
+	IDBUpperBowl
+	IDBLowerBowl
+	IDCLowerBar
+	IDFBar
+	IDGLower
+	IDHBar
+	IDMChevron
+	IDQTail
+	IDVFallingStick
+	IDWChevron
+	IDXRising
+	IDXFalling
+	IDYStem
+	IDZDiagonal
+	IDZeroRing
+	IDOneFlag
+	IDTwoDiagonal
+	IDDigitUpperLeft
+	IDDigitLowerRight
+	IDPeriod
+	IDExclamationStem
+	IDQuestionStem
+	IDQuestionDot
+	IDComma
+	IDApostrophe
+	IDColonUpper
+	IDHyphen
+	IDSlash
+	IDAmpersandUpper
+	IDAmpersandLower
+	IDAmpersandLeg
+	IDHashVerticals
+	IDHashBars
+	IDAcute
+	IDGrave
+	IDCircumflex
+	IDDiaeresis
+	IDTilde
+	IDRing
+	IDCedilla
+	IDTofu
+	IDMorph
+	IDSevenDiagonal
+	IDOBowl
+	IDCBowl
+	IDGBowl
 )
 
-var letterMap = map[byte]func() Letter{
-	' ': LetterSpace,
-	'S': LetterS,
-	'N': LetterN,
-	'A': LetterA,
-	'K': LetterK,
-	'E': LetterE,
-	'I': LetterI,
-	'D': LetterD,
-}
-
-// GetLetterMap returns a map to get the functions corresponding to each letter
-func GetLetterMap() map[byte]func() Letter {
-	newMap := make(map[byte]func() Letter)
-	for key, val := range letterMap {
-		newMap[key] = val
-	}
-	return newMap
+// letterMap holds the letters of DefaultFont
+var letterMap = map[rune]func() Letter{
+	' ':  LetterSpace,
+	'A':  LetterA,
+	'B':  LetterB,
+	'C':  LetterC,
+	'D':  LetterD,
+	'E':  LetterE,
+	'F':  LetterF,
+	'G':  LetterG,
+	'H':  LetterH,
+	'I':  LetterI,
+	'J':  LetterJ,
+	'K':  LetterK,
+	'L':  LetterL,
+	'M':  LetterM,
+	'N':  LetterN,
+	'O':  LetterO,
+	'P':  LetterP,
+	'Q':  LetterQ,
+	'R':  LetterR,
+	'S':  LetterS,
+	'T':  LetterT,
+	'U':  LetterU,
+	'V':  LetterV,
+	'W':  LetterW,
+	'X':  LetterX,
+	'Y':  LetterY,
+	'Z':  LetterZ,
+	'0':  LetterZero,
+	'1':  LetterOne,
+	'2':  LetterTwo,
+	'3':  LetterThree,
+	'4':  LetterFour,
+	'5':  LetterFive,
+	'6':  LetterSix,
+	'7':  LetterSeven,
+	'8':  LetterEight,
+	'9':  LetterNine,
+	'!':  LetterExclamation,
+	'?':  LetterQuestion,
+	'.':  LetterPeriod,
+	',':  LetterComma,
+	'\'': LetterApostrophe,
+	':':  LetterColon,
+	'-':  LetterHyphen,
+	'/':  LetterSlash,
+	'&':  LetterAmpersand,
+	'#':  LetterHash,
+
+	// Latin-1 accented letters
+	'À': Accented(LetterA, DiacriticGrave),
+	'Á': Accented(LetterA, DiacriticAcute),
+	'Â': Accented(LetterA, DiacriticCircumflex),
+	'Ã': Accented(LetterA, DiacriticTilde),
+	'Ä': Accented(LetterA, DiacriticDiaeresis),
+	'Å': Accented(LetterA, DiacriticRing),
+	'Ç': Accented(LetterC, DiacriticCedilla),
+	'È': Accented(LetterE, DiacriticGrave),
+	'É': Accented(LetterE, DiacriticAcute),
+	'Ê': Accented(LetterE, DiacriticCircumflex),
+	'Ë': Accented(LetterE, DiacriticDiaeresis),
+	'Ì': Accented(LetterI, DiacriticGrave),
+	'Í': Accented(LetterI, DiacriticAcute),
+	'Î': Accented(LetterI, DiacriticCircumflex),
+	'Ï': Accented(LetterI, DiacriticDiaeresis),
+	'Ñ': Accented(LetterN, DiacriticTilde),
+	'Ò': Accented(LetterO, DiacriticGrave),
+	'Ó': Accented(LetterO, DiacriticAcute),
+	'Ô': Accented(LetterO, DiacriticCircumflex),
+	'Õ': Accented(LetterO, DiacriticTilde),
+	'Ö': Accented(LetterO, DiacriticDiaeresis),
+	'Ù': Accented(LetterU, DiacriticGrave),
+	'Ú': Accented(LetterU, DiacriticAcute),
+	'Û': Accented(LetterU, DiacriticCircumflex),
+	'Ü': Accented(LetterU, DiacriticDiaeresis),
+	'Ý': Accented(LetterY, DiacriticAcute),
+
+	TofuRune: LetterTofu,
+}
+
+// GetLetterMap returns a map to get the functions corresponding to
+// each letter in DefaultFont
+func GetLetterMap() map[rune]func() Letter {
+	return DefaultFont.LetterMap()
 }
 
 // Letter defines a custom type for full letters. A letter is defined
 // as a slice of segments.
 type Letter []Segment
 
+// Advancer is implemented by segments that take up a different
+// amount of horizontal space to the font's width, such as punctuation.
+// The style is the one the segment is drawn with, which may not be
+// the font's.
+type Advancer interface {
+	AdvanceWidth(font *Font, style *Style) float64
+}
+
 /* Space */
 
 // LetterSpace returns a blank collection of segments
@@ -80,10 +202,6 @@ func LetterSpace() Letter {
 
 /* Letter S */
 
-// SStickLength is the length of the sticks on the tops and bottoms of
-// an S
-const SStickLength = 7
-
 // SegmentSUpperBar is the top bar on an S
 type SegmentSUpperBar struct {
 	segment
@@ -91,19 +209,19 @@ type SegmentSUpperBar struct {
 
 // Draw defines the behaviour of the segment
 func (seg *SegmentSUpperBar) Draw(gc CellDrawer, cell *Cell) bool {
-	fillColor := cell.DeathColors[1]
-	gc.SetFillColor(color.RGBA{fillColor.R, fillColor.G, fillColor.B, 0xff})
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
 	width := seg.Width()
 	gc.MoveTo(0, 0)
 	gc.LineTo(width-1, 0)
-	gc.LineTo(width-1, stickThickness+SStickLength)
-	gc.LineTo(width-1-stickThickness, stickThickness+SStickLength)
-	gc.LineTo(width-1-stickThickness, stickThickness)
-	gc.LineTo(0, stickThickness)
+	gc.LineTo(width-1, style.StickThickness+style.SStickLength)
+	gc.LineTo(width-1-style.StickThickness, style.StickThickness+style.SStickLength)
+	gc.LineTo(width-1-style.StickThickness, style.StickThickness)
+	gc.LineTo(0, style.StickThickness)
 	gc.LineTo(0, 0)
 	gc.Fill()
 	gc.Close()
-	return true
+	return changed
 }
 
 // ID returns the ID of the segment
@@ -116,20 +234,20 @@ type SegmentSLowerBar struct {
 
 // Draw defines the behaviour of the segment
 func (seg *SegmentSLowerBar) Draw(gc CellDrawer, cell *Cell) bool {
-	fillColor := cell.DeathColors[1]
-	gc.SetFillColor(color.RGBA{fillColor.R, fillColor.G, fillColor.B, 0xff})
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
 	width, height := seg.Width(), seg.Height()
 	gc.MoveTo(0, height-1)
 	gc.LineTo(width-1, height-1)
-	gc.LineTo(width-1, height-stickThickness-1)
-	gc.LineTo(stickThickness, height-stickThickness-1)
-	gc.LineTo(stickThickness, height-stickThickness-SStickLength-1)
-	gc.LineTo(0, height-stickThickness-SStickLength-1)
-	gc.LineTo(0, height-stickThickness-1)
+	gc.LineTo(width-1, height-style.StickThickness-1)
+	gc.LineTo(style.StickThickness, height-style.StickThickness-1)
+	gc.LineTo(style.StickThickness, height-style.StickThickness-style.SStickLength-1)
+	gc.LineTo(0, height-style.StickThickness-style.SStickLength-1)
+	gc.LineTo(0, height-style.StickThickness-1)
 	gc.LineTo(0, height-1)
 	gc.Fill()
 	gc.Close()
-	return true
+	return changed
 }
 
 // ID returns the ID of the segment
@@ -142,21 +260,24 @@ type SegmentSMiddle struct {
 
 // Draw defines the behaviour of the segment
 func (seg *SegmentSMiddle) Draw(gc CellDrawer, cell *Cell) bool {
-	fillColor := cell.DeathColors[1]
-	gc.SetFillColor(color.RGBA{fillColor.R, fillColor.G, fillColor.B, 0xff})
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
 	width, height := seg.Width(), seg.Height()
-	gc.MoveTo(0, stickThickness-0.5)
-	gc.LineTo(stickThickness, stickThickness-0.5)
-	gc.LineTo(stickThickness, stickThickness+9)
-	gc.LineTo(width-1, height-stickThickness-16)
-	gc.LineTo(width-1, height-stickThickness-0.5)
-	gc.LineTo(width-stickThickness-1, height-stickThickness-0.5)
-	gc.LineTo(width-stickThickness-1, height-stickThickness-10)
-	gc.LineTo(0, stickThickness+15)
-	gc.LineTo(0, stickThickness-0.5)
+	// how far the diagonal leaves the sticks below the top bar, on
+	// the inside and outside of the stick, and above the bottom bar
+	inner, outer := style.StickThickness/2, style.StickThickness*5/6
+	gc.MoveTo(0, style.StickThickness-0.5)
+	gc.LineTo(style.StickThickness, style.StickThickness-0.5)
+	gc.LineTo(style.StickThickness, style.StickThickness+inner)
+	gc.LineTo(width-1, height-style.StickThickness-1-outer)
+	gc.LineTo(width-1, height-style.StickThickness-0.5)
+	gc.LineTo(width-style.StickThickness-1, height-style.StickThickness-0.5)
+	gc.LineTo(width-style.StickThickness-1, height-style.StickThickness-1-inner)
+	gc.LineTo(0, style.StickThickness+outer)
+	gc.LineTo(0, style.StickThickness-0.5)
 	gc.Fill()
 	gc.Close()
-	return true
+	return changed
 }
 
 // ID returns the ID of the segment
@@ -176,17 +297,17 @@ type SegmentNLeftVert struct {
 
 // Draw defines the behaviour of the segment
 func (seg *SegmentNLeftVert) Draw(gc CellDrawer, cell *Cell) bool {
-	fillColor := cell.DeathColors[1]
-	gc.SetFillColor(color.RGBA{fillColor.R, fillColor.G, fillColor.B, 0xff})
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
 	height := seg.Height()
 	gc.MoveTo(0, 0)
-	gc.LineTo(stickThickness, 0)
-	gc.LineTo(stickThickness, height-1)
+	gc.LineTo(style.StickThickness, 0)
+	gc.LineTo(style.StickThickness, height-1)
 	gc.LineTo(0, height-1)
 	gc.LineTo(0, 0)
 	gc.Fill()
 	gc.Close()
-	return true
+	return changed
 }
 
 // ID returns the ID of the segment
@@ -199,17 +320,17 @@ type SegmentNBar struct {
 
 // Draw defines the behaviour of the segment
 func (seg *SegmentNBar) Draw(gc CellDrawer, cell *Cell) bool {
-	fillColor := cell.DeathColors[1]
-	gc.SetFillColor(color.RGBA{fillColor.R, fillColor.G, fillColor.B, 0xff})
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
 	width := seg.Width()
 	gc.MoveTo(0, 0)
 	gc.LineTo(width-1, 0)
-	gc.LineTo(width-1, stickThickness-1)
-	gc.LineTo(0, stickThickness-1)
+	gc.LineTo(width-1, style.StickThickness-1)
+	gc.LineTo(0, style.StickThickness-1)
 	gc.LineTo(0, 0)
 	gc.Fill()
 	gc.Close()
-	return true
+	return changed
 }
 
 // ID returns the ID of the segment
@@ -222,18 +343,18 @@ type SegmentNRightVert struct {
 
 // Draw defines the behaviour of the segment
 func (seg *SegmentNRightVert) Draw(gc CellDrawer, cell *Cell) bool {
-	fillColor := cell.DeathColors[1]
-	gc.SetFillColor(color.RGBA{fillColor.R, fillColor.G, fillColor.B, 0xff})
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
 	// gc.SetFillColor(color.RGBA{0x99, 0xff, 0x99, 0xff})
 	width, height := seg.Width(), seg.Height()
-	gc.MoveTo(width-stickThickness-1, 0)
+	gc.MoveTo(width-style.StickThickness-1, 0)
 	gc.LineTo(width-1, 0)
 	gc.LineTo(width-1, height-1)
-	gc.LineTo(width-stickThickness-1, height-1)
-	gc.LineTo(width-stickThickness-1, 0)
+	gc.LineTo(width-style.StickThickness-1, height-1)
+	gc.LineTo(width-style.StickThickness-1, 0)
 	gc.Fill()
 	gc.Close()
-	return true
+	return changed
 }
 
 // ID returns the ID of the segment
@@ -246,10 +367,6 @@ func LetterN() Letter {
 
 /* Letter A */
 
-// ABarUpperHeight is the height of the line through the centre of an
-// A
-const ABarUpperHeight = DefaultHeight - 35
-
 // SegmentARisingStick is one of the parts of an A
 //
 //    / /
@@ -262,23 +379,28 @@ type SegmentARisingStick struct {
 
 // Draw defines the behaviour of the segment
 func (seg *SegmentARisingStick) Draw(gc CellDrawer, cell *Cell) bool {
-	fillColor := cell.DeathColors[1]
-	gc.SetFillColor(color.RGBA{fillColor.R, fillColor.G, fillColor.B, 0xff})
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
 	// gc.SetFillColor(color.RGBA{0xff, 0x99, 0x99, 0xff})
 	width, height := seg.Width(), seg.Height()
+	barTop := height - style.ABarTopFromBottom
+	// the outside of the diagonal starts higher up than the inside,
+	// so that the diagonal is as thick as the stems
+	run := width - 1 - style.StickThickness
+	outerTop := barTop - diagonalOffset(run, barTop, style.StickThickness)
+	gradient := outerTop / run
 	gc.MoveTo(0, height-1)
-	gc.LineTo(stickThickness, height-1)
-	gc.LineTo(stickThickness, ABarUpperHeight)
-	gradient := (40 - height) / (stickThickness - width + 1) // (y2-y1)/(x1-x2) because y axis is reversed
-	gc.LineTo(width-1, height-35-gradient*(width-1-stickThickness))
+	gc.LineTo(style.StickThickness, height-1)
+	gc.LineTo(style.StickThickness, barTop)
+	gc.LineTo(width-1, barTop-gradient*run)
 	gc.LineTo(width-1, 0)
-	gc.LineTo(width-stickThickness-1, 0) // (x2, y2)
-	gc.LineTo(0, height-40)              // (x1, y1)
+	gc.LineTo(run, 0)
+	gc.LineTo(0, outerTop)
 	gc.LineTo(0, height-1)
 	gc.Fill()
 	gc.Close()
 
-	return true
+	return changed
 }
 
 // ID returns the ID of the segment
@@ -291,19 +413,20 @@ type SegmentABar struct {
 
 // Draw defines the behaviour of the segment
 func (seg *SegmentABar) Draw(gc CellDrawer, cell *Cell) bool {
-	fillColor := cell.DeathColors[1]
-	gc.SetFillColor(color.RGBA{fillColor.R, fillColor.G, fillColor.B, 0xff})
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
 	// gc.SetFillColor(color.RGBA{0x99, 0x99, 0xff, 0xff})
-	width := seg.Width()
-	gc.MoveTo(stickThickness-0.5, ABarUpperHeight)
-	gc.LineTo(width-stickThickness-0.5, ABarUpperHeight)
-	gc.LineTo(width-stickThickness-0.5, ABarUpperHeight+stickThickness)
-	gc.LineTo(stickThickness-0.5, ABarUpperHeight+stickThickness)
-	gc.LineTo(stickThickness-0.5, ABarUpperHeight)
+	width, height := seg.Width(), seg.Height()
+	barTop := height - style.ABarTopFromBottom
+	gc.MoveTo(style.StickThickness-0.5, barTop)
+	gc.LineTo(width-style.StickThickness-0.5, barTop)
+	gc.LineTo(width-style.StickThickness-0.5, barTop+style.StickThickness)
+	gc.LineTo(style.StickThickness-0.5, barTop+style.StickThickness)
+	gc.LineTo(style.StickThickness-0.5, barTop)
 	gc.Fill()
 	gc.Close()
 
-	return true
+	return changed
 }
 
 // ID returns the ID of the segment
@@ -323,18 +446,20 @@ type SegmentKUpper struct {
 
 // Draw defines the behaviour of the segment
 func (seg *SegmentKUpper) Draw(gc CellDrawer, cell *Cell) bool {
-	fillColor := cell.DeathColors[1]
-	gc.SetFillColor(color.RGBA{fillColor.R, fillColor.G, fillColor.B, 0xff})
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
 	width, height := seg.Width(), seg.Height()
-	gc.MoveTo(stickThickness-8.5, height/2-0.5)
-	gc.LineTo(width-1-stickThickness, 0)
+	// the arm starts from the middle of the stem
+	start := style.StickThickness/2 + 0.5
+	gc.MoveTo(start, height/2-0.5)
+	gc.LineTo(width-1-style.StickThickness, 0)
 	gc.LineTo(width-1, 0)
-	gc.LineTo(2*stickThickness-8.5, height/2-0.5)
-	gc.LineTo(stickThickness-8.5, height/2-0.5)
+	gc.LineTo(start+style.StickThickness, height/2-0.5)
+	gc.LineTo(start, height/2-0.5)
 	gc.Fill()
 	gc.Close()
 
-	return true
+	return changed
 }
 
 // ID returns the ID of the segment
@@ -347,18 +472,20 @@ type SegmentKLower struct {
 
 // Draw defines the behaviour of the segment
 func (seg *SegmentKLower) Draw(gc CellDrawer, cell *Cell) bool {
-	fillColor := cell.DeathColors[1]
-	gc.SetFillColor(color.RGBA{fillColor.R, fillColor.G, fillColor.B, 0xff})
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
 	width, height := seg.Width(), seg.Height()
-	gc.MoveTo(stickThickness-8.5, height/2-1.5)
-	gc.LineTo(width-1-stickThickness, height-1)
+	// the arm starts from the middle of the stem
+	start := style.StickThickness/2 + 0.5
+	gc.MoveTo(start, height/2-1.5)
+	gc.LineTo(width-1-style.StickThickness, height-1)
 	gc.LineTo(width-1, height-1)
-	gc.LineTo(2*stickThickness-8.5, height/2-1.5)
-	gc.LineTo(stickThickness-8.5, height/2-1.5)
+	gc.LineTo(start+style.StickThickness, height/2-1.5)
+	gc.LineTo(start, height/2-1.5)
 	gc.Fill()
 	gc.Close()
 
-	return true
+	return changed
 }
 
 // ID returns the ID of the segment
@@ -371,12 +498,6 @@ func LetterK() Letter {
 
 /* Letter E */
 
-// EPointDepth is how far in the dip on the left of an E goes in
-const EPointDepth = 13
-
-// EEdgeDepth is how far in the centre line of the E is from the right
-const EEdgeDepth = 5
-
 // SegmentELower is the lower bar on an E
 type SegmentELower struct {
 	segment
@@ -384,18 +505,18 @@ type SegmentELower struct {
 
 // Draw defines the behaviour of the segment
 func (seg *SegmentELower) Draw(gc CellDrawer, cell *Cell) bool {
-	fillColor := cell.DeathColors[1]
-	gc.SetFillColor(color.RGBA{fillColor.R, fillColor.G, fillColor.B, 0xff})
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
 	width, height := seg.Width(), seg.Height()
 	gc.MoveTo(0, height-1)
 	gc.LineTo(width-1, height-1)
-	gc.LineTo(width-1, height-1-stickThickness)
-	gc.LineTo(0, height-1-stickThickness)
+	gc.LineTo(width-1, height-1-style.StickThickness)
+	gc.LineTo(0, height-1-style.StickThickness)
 	gc.LineTo(0, height-1)
 	gc.Fill()
 	gc.Close()
 
-	return true
+	return changed
 }
 
 // ID returns the ID of the segment
@@ -408,26 +529,31 @@ type SegmentEMiddle struct {
 
 // Draw defines the behaviour of the segment
 func (seg *SegmentEMiddle) Draw(gc CellDrawer, cell *Cell) bool {
-	fillColor := cell.DeathColors[1]
-	gc.SetFillColor(color.RGBA{fillColor.R, fillColor.G, fillColor.B, 0xff})
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
 	width, height := seg.Width(), seg.Height()
-	// height := seg.Height()
-	gc.MoveTo(0, height-0.5-stickThickness)
-	gc.LineTo(EPointDepth-1, height/2-1)
-	gc.LineTo(0, stickThickness-1)
-	gc.LineTo(0, stickThickness-2)
-	gc.LineTo(stickThickness-1, stickThickness-2)
-	gc.LineTo(stickThickness-1, stickThickness-1)
-	gc.LineTo(stickThickness+EPointDepth-6, height/2-stickThickness/2-1)
-	gc.LineTo(width-1-EEdgeDepth, height/2-stickThickness/2-1)
-	gc.LineTo(width-1-EEdgeDepth, height/2+stickThickness/2-1)
-	gc.LineTo(stickThickness+EPointDepth-6, height/2+stickThickness/2-1)
-	gc.LineTo(stickThickness-1, height-0.5-stickThickness)
-	gc.LineTo(0, height-0.5-stickThickness)
+	// the inside of the point is a third of a stroke further in
+	// than the outside
+	innerPoint := style.StickThickness*2/3 + style.EPointDepth
+	// the top reaches a pixel into the bar, so that no seam shows
+	// between them
+	barBottom := style.StickThickness - 1
+	gc.MoveTo(0, height-0.5-style.StickThickness)
+	gc.LineTo(style.EPointDepth-1, height/2-1)
+	gc.LineTo(0, barBottom)
+	gc.LineTo(0, barBottom-1)
+	gc.LineTo(style.StickThickness-1, barBottom-1)
+	gc.LineTo(style.StickThickness-1, barBottom)
+	gc.LineTo(innerPoint, height/2-style.StickThickness/2-1)
+	gc.LineTo(width-1-style.EEdgeDepth, height/2-style.StickThickness/2-1)
+	gc.LineTo(width-1-style.EEdgeDepth, height/2+style.StickThickness/2-1)
+	gc.LineTo(innerPoint, height/2+style.StickThickness/2-1)
+	gc.LineTo(style.StickThickness-1, height-0.5-style.StickThickness)
+	gc.LineTo(0, height-0.5-style.StickThickness)
 	gc.Fill()
 	gc.Close()
 
-	return true
+	return changed
 }
 
 // ID returns the ID of the segment
@@ -440,9 +566,6 @@ func LetterE() Letter {
 
 /* Letter I */
 
-// IOverhang is how far the top bar of an I hangs over
-const IOverhang = 7
-
 // SegmentIMiddle is the line through the centre of an I
 type SegmentIMiddle struct {
 	segment
@@ -450,17 +573,17 @@ type SegmentIMiddle struct {
 
 // Draw defines the behaviour of the segment
 func (seg *SegmentIMiddle) Draw(gc CellDrawer, cell *Cell) bool {
-	fillColor := cell.DeathColors[1]
-	gc.SetFillColor(color.RGBA{fillColor.R, fillColor.G, fillColor.B, 0xff})
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
 	width, height := seg.Width(), seg.Height()
-	gc.MoveTo(width/2-1-stickThickness/2, 0)
-	gc.LineTo(width/2-1+stickThickness/2, 0)
-	gc.LineTo(width/2-1+stickThickness/2, height-1)
-	gc.LineTo(width/2-1-stickThickness/2, height-1)
-	gc.LineTo(width/2-1-stickThickness/2, 0)
+	gc.MoveTo(width/2-1-style.StickThickness/2, 0)
+	gc.LineTo(width/2-1+style.StickThickness/2, 0)
+	gc.LineTo(width/2-1+style.StickThickness/2, height-1)
+	gc.LineTo(width/2-1-style.StickThickness/2, height-1)
+	gc.LineTo(width/2-1-style.StickThickness/2, 0)
 	gc.Fill()
 	gc.Close()
-	return true
+	return changed
 }
 
 // ID returns the ID of the segment
@@ -473,17 +596,17 @@ type SegmentITop struct {
 
 // Draw defines the behaviour of the segment
 func (seg *SegmentITop) Draw(gc CellDrawer, cell *Cell) bool {
-	fillColor := cell.DeathColors[1]
-	gc.SetFillColor(color.RGBA{fillColor.R, fillColor.G, fillColor.B, 0xff})
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
 	width := seg.Width()
-	gc.MoveTo(width/2-1-stickThickness/2-IOverhang, 0)
-	gc.LineTo(width/2-1+stickThickness/2+IOverhang, 0)
-	gc.LineTo(width/2-1+stickThickness/2+IOverhang, stickThickness-1)
-	gc.LineTo(width/2-1-stickThickness/2-IOverhang, stickThickness-1)
-	gc.LineTo(width/2-1-stickThickness/2-IOverhang, 0)
+	gc.MoveTo(width/2-1-style.StickThickness/2-style.IOverhang, 0)
+	gc.LineTo(width/2-1+style.StickThickness/2+style.IOverhang, 0)
+	gc.LineTo(width/2-1+style.StickThickness/2+style.IOverhang, style.StickThickness-1)
+	gc.LineTo(width/2-1-style.StickThickness/2-style.IOverhang, style.StickThickness-1)
+	gc.LineTo(width/2-1-style.StickThickness/2-style.IOverhang, 0)
 	gc.Fill()
 	gc.Close()
-	return true
+	return changed
 }
 
 // ID returns the ID of the segment
@@ -496,17 +619,17 @@ type SegmentIBottom struct {
 
 // Draw defines the behaviour of the segment
 func (seg *SegmentIBottom) Draw(gc CellDrawer, cell *Cell) bool {
-	fillColor := cell.DeathColors[1]
-	gc.SetFillColor(color.RGBA{fillColor.R, fillColor.G, fillColor.B, 0xff})
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
 	width, height := seg.Width(), seg.Height()
-	gc.MoveTo(width/2-1-stickThickness/2-IOverhang, height-1)
-	gc.LineTo(width/2-1+stickThickness/2+IOverhang, height-1)
-	gc.LineTo(width/2-1+stickThickness/2+IOverhang, height-stickThickness-1)
-	gc.LineTo(width/2-1-stickThickness/2-IOverhang, height-stickThickness-1)
-	gc.LineTo(width/2-1-stickThickness/2-IOverhang, height-1)
+	gc.MoveTo(width/2-1-style.StickThickness/2-style.IOverhang, height-1)
+	gc.LineTo(width/2-1+style.StickThickness/2+style.IOverhang, height-1)
+	gc.LineTo(width/2-1+style.StickThickness/2+style.IOverhang, height-style.StickThickness-1)
+	gc.LineTo(width/2-1-style.StickThickness/2-style.IOverhang, height-style.StickThickness-1)
+	gc.LineTo(width/2-1-style.StickThickness/2-style.IOverhang, height-1)
 	gc.Fill()
 	gc.Close()
-	return true
+	return changed
 }
 
 // ID returns the ID of the segment
@@ -519,12 +642,6 @@ func LetterI() Letter {
 
 /* Letter D */
 
-// Sizes a D
-const (
-	DGapThinnest    = 5
-	DDiagonalHeight = DefaultHeight / 4
-)
-
 // SegmentDCurve is the curved line of a D
 type SegmentDCurve struct {
 	segment
@@ -532,25 +649,32 @@ type SegmentDCurve struct {
 
 // Draw defines the behaviour of the segment
 func (seg *SegmentDCurve) Draw(gc CellDrawer, cell *Cell) bool {
-	fillColor := cell.DeathColors[1]
-	gc.SetFillColor(color.RGBA{fillColor.R, fillColor.G, fillColor.B, 0xff})
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
 	width, height := seg.Width(), seg.Height()
+	// the outside corners are quarter ellipses DDiagonalHeight tall.
+	// The inside corners curve round the same distance in each
+	// direction, leaving the top and bottom of the gap flat for
+	// DGapThinnest.
+	innerLeft := style.StickThickness - 1 + style.DGapThinnest
+	innerRight := width - 1 - style.StickThickness
+	innerDrop := math.Max(0, innerRight-innerLeft)
 	gc.MoveTo(0, 0)
-	gc.LineTo(width-stickThickness-1, 0)
-	gc.LineTo(width-1, DDiagonalHeight-1)
-	gc.LineTo(width-1, height-DDiagonalHeight-1)
-	gc.LineTo(width-stickThickness-1, height-1)
+	gc.LineTo(width-style.StickThickness-1, 0)
+	ellipseCornerTo(gc, width-style.StickThickness-1, 0, width-1, 0, width-1, style.DDiagonalHeight-1)
+	gc.LineTo(width-1, height-style.DDiagonalHeight-1)
+	ellipseCornerTo(gc, width-1, height-style.DDiagonalHeight-1, width-1, height-1, width-style.StickThickness-1, height-1)
 	gc.LineTo(0, height-1)
-	gc.LineTo(0, height-stickThickness-1)
-	gc.LineTo(stickThickness-1+DGapThinnest, height-stickThickness-1)
-	gc.LineTo(width-1-stickThickness, height-11-stickThickness)
-	gc.LineTo(width-1-stickThickness, stickThickness+9)
-	gc.LineTo(stickThickness-1+DGapThinnest, stickThickness-1)
-	gc.LineTo(0, stickThickness-1)
+	gc.LineTo(0, height-style.StickThickness-1)
+	gc.LineTo(innerLeft, height-style.StickThickness-1)
+	gc.QuadCurveTo(innerRight, height-style.StickThickness-1, innerRight, height-style.StickThickness-1-innerDrop)
+	gc.LineTo(innerRight, style.StickThickness-1+innerDrop)
+	gc.QuadCurveTo(innerRight, style.StickThickness-1, innerLeft, style.StickThickness-1)
+	gc.LineTo(0, style.StickThickness-1)
 	gc.LineTo(0, 0)
 	gc.Fill()
 	gc.Close()
-	return true
+	return changed
 }
 
 // ID returns the ID of the segment
@@ -560,3 +684,635 @@ func (seg *SegmentDCurve) ID() SegmentID { return IDDCurve }
 func LetterD() Letter {
 	return Letter{&SegmentNLeftVert{}, &SegmentDCurve{}}
 }
+
+/* Letter B */
+
+// SegmentBUpperBowl is the top bowl of a B, including the bar
+// through the middle
+type SegmentBUpperBowl struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentBUpperBowl) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := seg.Width(), seg.Height()
+	gc.MoveTo(0, 0)
+	gc.LineTo(width-1-style.StickThickness, 0)
+	gc.LineTo(width-1, style.BChamfer)
+	gc.LineTo(width-1, height/2+style.StickThickness/2-1)
+	gc.LineTo(0, height/2+style.StickThickness/2-1)
+	gc.LineTo(0, height/2-style.StickThickness/2-1)
+	gc.LineTo(width-1-style.StickThickness, height/2-style.StickThickness/2-1)
+	gc.LineTo(width-1-style.StickThickness, style.StickThickness-1)
+	gc.LineTo(0, style.StickThickness-1)
+	gc.LineTo(0, 0)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentBUpperBowl) ID() SegmentID { return IDBUpperBowl }
+
+// SegmentBLowerBowl is the bottom bowl of a B
+type SegmentBLowerBowl struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentBLowerBowl) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := seg.Width(), seg.Height()
+	gc.MoveTo(width-1-style.StickThickness, height/2+style.StickThickness/2-1)
+	gc.LineTo(width-1, height/2+style.StickThickness/2-1)
+	gc.LineTo(width-1, height-1-style.BChamfer)
+	gc.LineTo(width-1-style.StickThickness, height-1)
+	gc.LineTo(0, height-1)
+	gc.LineTo(0, height-1-style.StickThickness)
+	gc.LineTo(width-1-style.StickThickness, height-1-style.StickThickness)
+	gc.LineTo(width-1-style.StickThickness, height/2+style.StickThickness/2-1)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentBLowerBowl) ID() SegmentID { return IDBLowerBowl }
+
+// LetterB returns all the segments for the letter B
+func LetterB() Letter {
+	return Letter{&SegmentNLeftVert{}, &SegmentBUpperBowl{}, &SegmentBLowerBowl{}}
+}
+
+/* Round Letters */
+
+// The bowls of O, Q, C and G are rounded into quarter ellipses half
+// the width of the letter across and DDiagonalHeight tall, so that
+// they are rounder than the corners of a D or a 0. The counters are
+// rounded the same amount on the inside.
+
+// bowlLeftOutside adds the outside of the left half of a bowl to the
+// path, anticlockwise from the middle of the bottom to the middle of
+// the top
+func bowlLeftOutside(gc CellDrawer, width, height float64, style *Style) {
+	centre, drop := (width-1)/2, style.DDiagonalHeight-1
+	ellipseCornerTo(gc, centre, height-1, 0, height-1, 0, height-1-drop)
+	gc.LineTo(0, drop)
+	ellipseCornerTo(gc, 0, drop, 0, 0, centre, 0)
+}
+
+// bowlLeftInside adds the inside of the left half of a bowl to the
+// path, clockwise from the middle of the top to the middle of the
+// bottom
+func bowlLeftInside(gc CellDrawer, width, height float64, style *Style) {
+	centre, drop := (width-1)/2, style.DDiagonalHeight-1
+	top, bottom := style.StickThickness-1, height-1-style.StickThickness
+	ellipseCornerTo(gc, centre, top, style.StickThickness, top, style.StickThickness, top+drop)
+	gc.LineTo(style.StickThickness, bottom-drop)
+	ellipseCornerTo(gc, style.StickThickness, bottom-drop, style.StickThickness, bottom, centre, bottom)
+}
+
+/* Letter C */
+
+// SegmentCBowl is the curve of a C, which turns down at the top and
+// up at the bottom on the right hand side
+type SegmentCBowl struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentCBowl) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := seg.Width(), seg.Height()
+	top, bottom := style.StickThickness-1, height-1-style.StickThickness
+	gc.MoveTo(width-1, 0)
+	gc.LineTo(width-1, top+style.SStickLength)
+	gc.LineTo(width-1-style.StickThickness, top+style.SStickLength)
+	gc.LineTo(width-1-style.StickThickness, top)
+	gc.LineTo((width-1)/2, top)
+	bowlLeftInside(gc, width, height, style)
+	gc.LineTo(width-1-style.StickThickness, bottom)
+	gc.LineTo(width-1-style.StickThickness, bottom-style.SStickLength)
+	gc.LineTo(width-1, bottom-style.SStickLength)
+	gc.LineTo(width-1, height-1)
+	gc.LineTo((width-1)/2, height-1)
+	bowlLeftOutside(gc, width, height, style)
+	gc.LineTo(width-1, 0)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentCBowl) ID() SegmentID { return IDCBowl }
+
+// SegmentCLowerBar is the bottom bar on a C, which turns up on the
+// right hand side.
+//
+// Deprecated: LetterC is drawn with SegmentCBowl.
+type SegmentCLowerBar struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentCLowerBar) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := seg.Width(), seg.Height()
+	gc.MoveTo(0, height-1)
+	gc.LineTo(width-1, height-1)
+	gc.LineTo(width-1, height-style.StickThickness-style.SStickLength-1)
+	gc.LineTo(width-1-style.StickThickness, height-style.StickThickness-style.SStickLength-1)
+	gc.LineTo(width-1-style.StickThickness, height-style.StickThickness-1)
+	gc.LineTo(0, height-style.StickThickness-1)
+	gc.LineTo(0, height-1)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentCLowerBar) ID() SegmentID { return IDCLowerBar }
+
+// LetterC returns all the segments for the letter C
+func LetterC() Letter {
+	return Letter{&SegmentCBowl{}}
+}
+
+/* Letter F */
+
+// SegmentFBar is the middle bar of an F
+type SegmentFBar struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentFBar) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := seg.Width(), seg.Height()
+	gc.MoveTo(style.StickThickness-1, height/2-style.StickThickness/2-1)
+	gc.LineTo(width-1-style.EEdgeDepth, height/2-style.StickThickness/2-1)
+	gc.LineTo(width-1-style.EEdgeDepth, height/2+style.StickThickness/2-1)
+	gc.LineTo(style.StickThickness-1, height/2+style.StickThickness/2-1)
+	gc.LineTo(style.StickThickness-1, height/2-style.StickThickness/2-1)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentFBar) ID() SegmentID { return IDFBar }
+
+// LetterF returns all the segments for the letter F
+func LetterF() Letter {
+	return Letter{&SegmentNLeftVert{}, &SegmentNBar{}, &SegmentFBar{}}
+}
+
+/* Letter G */
+
+// SegmentGBowl is the curve of a G, with the spur turning in from
+// the right hand side below the middle
+type SegmentGBowl struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentGBowl) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := seg.Width(), seg.Height()
+	top, bottom := style.StickThickness-1, height-1-style.StickThickness
+	gc.MoveTo(width-1, 0)
+	gc.LineTo(width-1, top)
+	gc.LineTo((width-1)/2, top)
+	bowlLeftInside(gc, width, height, style)
+	gc.LineTo(width-1-style.StickThickness, bottom)
+	gc.LineTo(width-1-style.StickThickness, height/2+style.StickThickness/2-1)
+	gc.LineTo(width-1-style.GSpurDepth, height/2+style.StickThickness/2-1)
+	gc.LineTo(width-1-style.GSpurDepth, height/2-style.StickThickness/2-1)
+	gc.LineTo(width-1, height/2-style.StickThickness/2-1)
+	gc.LineTo(width-1, height-1)
+	gc.LineTo((width-1)/2, height-1)
+	bowlLeftOutside(gc, width, height, style)
+	gc.LineTo(width-1, 0)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentGBowl) ID() SegmentID { return IDGBowl }
+
+// SegmentGLower is the bottom bar and spur of a G.
+//
+// Deprecated: LetterG is drawn with SegmentGBowl.
+type SegmentGLower struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentGLower) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := seg.Width(), seg.Height()
+	gc.MoveTo(0, height-1)
+	gc.LineTo(width-1, height-1)
+	gc.LineTo(width-1, height/2-style.StickThickness/2-1)
+	gc.LineTo(width-1-style.GSpurDepth, height/2-style.StickThickness/2-1)
+	gc.LineTo(width-1-style.GSpurDepth, height/2+style.StickThickness/2-1)
+	gc.LineTo(width-1-style.StickThickness, height/2+style.StickThickness/2-1)
+	gc.LineTo(width-1-style.StickThickness, height-1-style.StickThickness)
+	gc.LineTo(0, height-1-style.StickThickness)
+	gc.LineTo(0, height-1)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentGLower) ID() SegmentID { return IDGLower }
+
+// LetterG returns all the segments for the letter G
+func LetterG() Letter {
+	return Letter{&SegmentGBowl{}}
+}
+
+/* Letter H */
+
+// SegmentHBar is the bar between the two verticals of an H
+type SegmentHBar struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentHBar) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := seg.Width(), seg.Height()
+	gc.MoveTo(style.StickThickness-0.5, height/2-style.StickThickness/2-1)
+	gc.LineTo(width-style.StickThickness-0.5, height/2-style.StickThickness/2-1)
+	gc.LineTo(width-style.StickThickness-0.5, height/2+style.StickThickness/2-1)
+	gc.LineTo(style.StickThickness-0.5, height/2+style.StickThickness/2-1)
+	gc.LineTo(style.StickThickness-0.5, height/2-style.StickThickness/2-1)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentHBar) ID() SegmentID { return IDHBar }
+
+// LetterH returns all the segments for the letter H
+func LetterH() Letter {
+	return Letter{&SegmentNLeftVert{}, &SegmentHBar{}, &SegmentNRightVert{}}
+}
+
+/* Letter J */
+
+// LetterJ returns all the segments for the letter J
+func LetterJ() Letter {
+	return Letter{&SegmentNRightVert{}, &SegmentSLowerBar{}}
+}
+
+/* Letter L */
+
+// LetterL returns all the segments for the letter L
+func LetterL() Letter {
+	return Letter{&SegmentNLeftVert{}, &SegmentELower{}}
+}
+
+/* Letter M */
+
+// SegmentMChevron is the V shape hanging between the verticals of
+// an M
+type SegmentMChevron struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentMChevron) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width := seg.Width()
+	gc.MoveTo(0, 0)
+	gc.LineTo(style.StickThickness, 0)
+	gc.LineTo(width/2-1, style.MVertexHeight-style.StickThickness)
+	gc.LineTo(width-1-style.StickThickness, 0)
+	gc.LineTo(width-1, 0)
+	gc.LineTo(width-1, style.StickThickness)
+	gc.LineTo(width/2-1, style.MVertexHeight)
+	gc.LineTo(0, style.StickThickness)
+	gc.LineTo(0, 0)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentMChevron) ID() SegmentID { return IDMChevron }
+
+// LetterM returns all the segments for the letter M
+func LetterM() Letter {
+	return Letter{&SegmentNLeftVert{}, &SegmentMChevron{}, &SegmentNRightVert{}}
+}
+
+/* Letter O */
+
+// SegmentOBowl is the ring of an O, which is also the bowl of a Q
+type SegmentOBowl struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentOBowl) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := seg.Width(), seg.Height()
+	centre, drop := (width-1)/2, style.DDiagonalHeight-1
+	top, bottom := style.StickThickness-1, height-1-style.StickThickness
+	right := width - 1 - style.StickThickness
+	// outside, clockwise from the top
+	gc.MoveTo(centre, 0)
+	ellipseCornerTo(gc, centre, 0, width-1, 0, width-1, drop)
+	gc.LineTo(width-1, height-1-drop)
+	ellipseCornerTo(gc, width-1, height-1-drop, width-1, height-1, centre, height-1)
+	bowlLeftOutside(gc, width, height, style)
+	// inside, anticlockwise
+	gc.MoveTo(centre, top)
+	bowlLeftInside(gc, width, height, style)
+	ellipseCornerTo(gc, centre, bottom, right, bottom, right, bottom-drop)
+	gc.LineTo(right, top+drop)
+	ellipseCornerTo(gc, right, top+drop, right, top, centre, top)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentOBowl) ID() SegmentID { return IDOBowl }
+
+// LetterO returns all the segments for the letter O
+func LetterO() Letter {
+	return Letter{&SegmentOBowl{}}
+}
+
+/* Letter P */
+
+// LetterP returns all the segments for the letter P
+func LetterP() Letter {
+	return Letter{&SegmentNLeftVert{}, &SegmentBUpperBowl{}}
+}
+
+/* Letter Q */
+
+// SegmentQTail is the diagonal tail that cuts through the bottom
+// right of a Q. It starts below the middle of the bowl and is half as
+// thick as the other strokes, so that the counter stays open and the
+// diagonal can still be seen inside it however heavy the strokes are.
+type SegmentQTail struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentQTail) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	thickness := style.StickThickness / 2
+	width, height := seg.Width(), seg.Height()
+	top := height * 5 / 8
+	gc.MoveTo(width/2-1, top)
+	gc.LineTo(width/2-1+thickness, top)
+	gc.LineTo(width-1, height-1)
+	gc.LineTo(width-1-thickness, height-1)
+	gc.LineTo(width/2-1, top)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentQTail) ID() SegmentID { return IDQTail }
+
+// LetterQ returns all the segments for the letter Q
+func LetterQ() Letter {
+	return Letter{&SegmentOBowl{}, &SegmentQTail{}}
+}
+
+/* Letter R */
+
+// LetterR returns all the segments for the letter R
+func LetterR() Letter {
+	return Letter{&SegmentNLeftVert{}, &SegmentBUpperBowl{}, &SegmentKLower{}}
+}
+
+/* Letter T */
+
+// LetterT returns all the segments for the letter T
+func LetterT() Letter {
+	return Letter{&SegmentNBar{}, &SegmentIMiddle{}}
+}
+
+/* Letter U */
+
+// LetterU returns all the segments for the letter U
+func LetterU() Letter {
+	return Letter{&SegmentNLeftVert{}, &SegmentELower{}, &SegmentNRightVert{}}
+}
+
+/* Letter V */
+
+// SegmentVFallingStick is the left hand part of a V. It is an A's
+// rising stick turned upside down.
+//
+//  | |
+//  | |
+//   \ \
+//    \ \
+type SegmentVFallingStick struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentVFallingStick) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := seg.Width(), seg.Height()
+	barBottom := style.ABarTopFromBottom - 1
+	run := width - 1 - style.StickThickness
+	outerBottom := barBottom + diagonalOffset(run, height-1-barBottom, style.StickThickness)
+	gradient := (height - 1 - outerBottom) / run
+	gc.MoveTo(0, 0)
+	gc.LineTo(style.StickThickness, 0)
+	gc.LineTo(style.StickThickness, barBottom)
+	gc.LineTo(width-1, barBottom+gradient*run)
+	gc.LineTo(width-1, height-1)
+	gc.LineTo(run, height-1)
+	gc.LineTo(0, outerBottom)
+	gc.LineTo(0, 0)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentVFallingStick) ID() SegmentID { return IDVFallingStick }
+
+// LetterV returns all the segments for the letter V
+func LetterV() Letter {
+	return Letter{&SegmentVFallingStick{}, &SegmentNRightVert{}}
+}
+
+/* Letter W */
+
+// SegmentWChevron is the upturned V shape between the verticals of a
+// W
+type SegmentWChevron struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentWChevron) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := seg.Width(), seg.Height()
+	gc.MoveTo(0, height-1)
+	gc.LineTo(style.StickThickness, height-1)
+	gc.LineTo(width/2-1, height-1-style.WVertexHeight+style.StickThickness)
+	gc.LineTo(width-1-style.StickThickness, height-1)
+	gc.LineTo(width-1, height-1)
+	gc.LineTo(width-1, height-1-style.StickThickness)
+	gc.LineTo(width/2-1, height-1-style.WVertexHeight)
+	gc.LineTo(0, height-1-style.StickThickness)
+	gc.LineTo(0, height-1)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentWChevron) ID() SegmentID { return IDWChevron }
+
+// LetterW returns all the segments for the letter W
+func LetterW() Letter {
+	return Letter{&SegmentNLeftVert{}, &SegmentWChevron{}, &SegmentNRightVert{}}
+}
+
+/* Letter X */
+
+// SegmentXRising is the stroke of an X running from the bottom left
+// to the top right
+type SegmentXRising struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentXRising) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := seg.Width(), seg.Height()
+	gc.MoveTo(0, height-1)
+	gc.LineTo(width-1-style.StickThickness, 0)
+	gc.LineTo(width-1, 0)
+	gc.LineTo(style.StickThickness, height-1)
+	gc.LineTo(0, height-1)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentXRising) ID() SegmentID { return IDXRising }
+
+// SegmentXFalling is the stroke of an X running from the top left to
+// the bottom right
+type SegmentXFalling struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentXFalling) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := seg.Width(), seg.Height()
+	gc.MoveTo(0, 0)
+	gc.LineTo(style.StickThickness, 0)
+	gc.LineTo(width-1, height-1)
+	gc.LineTo(width-1-style.StickThickness, height-1)
+	gc.LineTo(0, 0)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentXFalling) ID() SegmentID { return IDXFalling }
+
+// LetterX returns all the segments for the letter X
+func LetterX() Letter {
+	return Letter{&SegmentXRising{}, &SegmentXFalling{}}
+}
+
+/* Letter Y */
+
+// SegmentYStem is the vertical line below the arms of a Y
+type SegmentYStem struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentYStem) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := seg.Width(), seg.Height()
+	gc.MoveTo(width/2-1-style.StickThickness/2, style.MVertexHeight-style.StickThickness)
+	gc.LineTo(width/2-1+style.StickThickness/2, style.MVertexHeight-style.StickThickness)
+	gc.LineTo(width/2-1+style.StickThickness/2, height-1)
+	gc.LineTo(width/2-1-style.StickThickness/2, height-1)
+	gc.LineTo(width/2-1-style.StickThickness/2, style.MVertexHeight-style.StickThickness)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentYStem) ID() SegmentID { return IDYStem }
+
+// LetterY returns all the segments for the letter Y
+func LetterY() Letter {
+	return Letter{&SegmentMChevron{}, &SegmentYStem{}}
+}
+
+/* Letter Z */
+
+// SegmentZDiagonal is the diagonal between the bars of a Z
+type SegmentZDiagonal struct {
+	segment
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentZDiagonal) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := seg.Width(), seg.Height()
+	gc.MoveTo(width-1-style.StickThickness, style.StickThickness-1)
+	gc.LineTo(width-1, style.StickThickness-1)
+	gc.LineTo(style.StickThickness, height-1-style.StickThickness)
+	gc.LineTo(0, height-1-style.StickThickness)
+	gc.LineTo(width-1-style.StickThickness, style.StickThickness-1)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentZDiagonal) ID() SegmentID { return IDZDiagonal }
+
+// LetterZ returns all the segments for the letter Z
+func LetterZ() Letter {
+	return Letter{&SegmentNBar{}, &SegmentZDiagonal{}, &SegmentELower{}}
+}
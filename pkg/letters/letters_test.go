@@ -0,0 +1,178 @@
+package letters
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"testing"
+	"time"
+
+	"github.com/llgcode/draw2d/draw2dimg"
+)
+
+// glyphScale is how much larger than designed the letters are drawn
+// in tests, so that antialiasing doesn't reach the probes
+const glyphScale = 4
+
+// drawGlyph draws a letter from the font in the given style, solid
+// and without a background, at glyphScale. The cell is surrounded by
+// a margin of its own height, so that anything drawn outside of the
+// cell can be seen. It returns the image and the rectangle of the
+// cell within it.
+func drawGlyph(letter Letter, font *Font, style Style) (*image.RGBA, image.Rectangle) {
+	width, height := font.Width*glyphScale, font.Height*glyphScale
+	cellRect := image.Rect(0, 0, int(width), int(height)).Add(image.Pt(int(height), int(height)))
+	cell := NewCell([2]float64{height, height}, [2]float64{height + width, 2 * height}, letter, ColorsDeath, ColorsParadox, font)
+	cell.Style = style
+	cell.Lifecycle = Lifecycle{}
+	cell.Clock = NewManualClock(time.Unix(0, 0))
+
+	img := image.NewRGBA(image.Rect(0, 0, int(width+2*height), int(3*height)))
+	cell.Draw(draw2dimg.NewGraphicContext(img))
+	return img, cellRect
+}
+
+// checkGlyphs checks that each of the runes has a letter in
+// DefaultFont which draws something inside of its cell and nothing
+// outside of it, that no two of them look the same, and that every
+// segment type has a single ID
+func checkGlyphs(t *testing.T, runes []rune) {
+	t.Helper()
+	ids := make(map[string]SegmentID)
+	drawn := make(map[string]rune)
+	for _, r := range runes {
+		letterFunc, ok := DefaultFont.LookupLetter(r)
+		if !ok {
+			t.Errorf("'%c' is not registered", r)
+			continue
+		}
+		letter := letterFunc()
+		if len(letter) == 0 {
+			t.Errorf("'%c' has no segments", r)
+			continue
+		}
+		for _, seg := range letter {
+			name := fmt.Sprintf("%T", seg)
+			if id, ok := ids[name]; ok && id != seg.ID() {
+				t.Errorf("%s in '%c' has ID %d, but had ID %d before", name, r, seg.ID(), id)
+			}
+			ids[name] = seg.ID()
+		}
+
+		img, cellRect := drawGlyph(letter, DefaultFont, DefaultStyle)
+		inside, outside := 0, 0
+		for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+			for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+				if img.RGBAAt(x, y).A == 0 {
+					continue
+				}
+				if (image.Point{x, y}).In(cellRect) {
+					inside++
+				} else {
+					outside++
+				}
+			}
+		}
+		if inside == 0 {
+			t.Errorf("'%c' draws nothing", r)
+		}
+		if outside != 0 {
+			t.Errorf("'%c' draws %d pixels outside of its cell", r, outside)
+		}
+		if other, ok := drawn[string(img.Pix)]; ok {
+			t.Errorf("'%c' looks the same as '%c'", r, other)
+		}
+		drawn[string(img.Pix)] = r
+	}
+}
+
+func TestUppercaseLetters(t *testing.T) {
+	var runes []rune
+	for r := 'A'; r <= 'Z'; r++ {
+		runes = append(runes, r)
+	}
+	checkGlyphs(t, runes)
+}
+
+func TestSegmentIDsUnique(t *testing.T) {
+	// every segment type in DefaultFont has its own ID
+	types := make(map[SegmentID]string)
+	for r, letterFunc := range DefaultFont.LetterMap() {
+		for _, seg := range letterFunc() {
+			name := fmt.Sprintf("%T", seg)
+			if other, ok := types[seg.ID()]; ok && other != name {
+				t.Errorf("%s in '%c' has the same ID as %s", name, r, other)
+			}
+			types[seg.ID()] = name
+		}
+	}
+}
+
+func TestSpaceIsBlank(t *testing.T) {
+	letterFunc, ok := DefaultFont.LookupLetter(' ')
+	if !ok {
+		t.Fatal("' ' is not registered")
+	}
+	img, _ := drawGlyph(letterFunc(), DefaultFont, DefaultStyle)
+	if !bytes.Equal(img.Pix, make([]byte, len(img.Pix))) {
+		t.Error("' ' draws something")
+	}
+}
+
+func TestDigits(t *testing.T) {
+	// the digits are checked along with the letters, so that none of
+	// them can be mistaken for a letter
+	var runes []rune
+	for r := '0'; r <= '9'; r++ {
+		runes = append(runes, r)
+	}
+	for r := 'A'; r <= 'Z'; r++ {
+		runes = append(runes, r)
+	}
+	checkGlyphs(t, runes)
+
+	// the leg of the 7 reaches the baseline
+	letterFunc, _ := DefaultFont.LookupLetter('7')
+	img, cellRect := drawGlyph(letterFunc(), DefaultFont, DefaultStyle)
+	baseline := cellRect.Max.Y - glyphScale/2
+	found := false
+	for x := cellRect.Min.X; x < cellRect.Max.X; x++ {
+		if img.RGBAAt(x, baseline).A == 0xff {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Error("the leg of the 7 doesn't reach the baseline")
+	}
+}
+
+func TestRoundLetterCorners(t *testing.T) {
+	// the corners of the cell are left empty outside of the curves,
+	// except where the letter has a terminal or a tail
+	w, h := DefaultWidth, DefaultHeight
+	topLeft, topRight := [2]float64{1, 1}, [2]float64{w - 2, 1}
+	bottomLeft, bottomRight := [2]float64{1, h - 2}, [2]float64{w - 2, h - 2}
+	tests := []struct {
+		char    rune
+		corners [][2]float64
+	}{
+		{'O', [][2]float64{topLeft, topRight, bottomLeft, bottomRight}},
+		{'Q', [][2]float64{topLeft, topRight, bottomLeft}},
+		{'C', [][2]float64{topLeft, bottomLeft}},
+		{'G', [][2]float64{topLeft, bottomLeft}},
+	}
+	for _, style := range []Style{LightStyle, RegularStyle, BlackStyle} {
+		for _, test := range tests {
+			letterFunc, _ := DefaultFont.LookupLetter(test.char)
+			img, cellRect := drawGlyph(letterFunc(), DefaultFont, style)
+			for _, corner := range test.corners {
+				x := cellRect.Min.X + int(corner[0]*glyphScale+glyphScale/2)
+				y := cellRect.Min.Y + int(corner[1]*glyphScale+glyphScale/2)
+				if img.RGBAAt(x, y).A != 0 {
+					t.Errorf("%c at weight %v is filled in the corner at (%v, %v)", test.char, style.StickThickness, corner[0], corner[1])
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package letters
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/llgcode/draw2d"
+)
+
+// SetLetter changes the letter drawn in the cell. Segments that the
+// new letter shares with the current one, going by their ID, stay
+// where they are. The rest of the current segments fade out and the
+// new letter's other segments fade in.
+func (cell *Cell) SetLetter(letter Letter) {
+	now := cell.now()
+	font := cell.Font
+	if font == nil {
+		font = DefaultFont
+	}
+
+	// segments that can still be kept, grouped by ID so that a
+	// letter using the same segment twice keeps both
+	available := map[SegmentID][]int{}
+	for i, seg := range cell.Segments {
+		if l, ok := seg.(lifecycler); ok && l.fadingOut() {
+			continue
+		}
+		available[seg.ID()] = append(available[seg.ID()], i)
+	}
+
+	kept := make([]bool, len(cell.Segments))
+	segments := []Segment{}
+	for _, seg := range letter {
+		if indices := available[seg.ID()]; len(indices) > 0 {
+			available[seg.ID()] = indices[1:]
+			kept[indices[0]] = true
+			segments = append(segments, cell.Segments[indices[0]])
+			continue
+		}
+		if r, ok := seg.(resizer); ok {
+			r.resize(font.Width, font.Height)
+		}
+		segments = append(segments, seg)
+	}
+
+	// everything else fades out, and is removed once it has faded
+	for i, seg := range cell.Segments {
+		if kept[i] {
+			continue
+		}
+		if l, ok := seg.(lifecycler); ok && !l.faded(now, cell.Lifecycle) {
+			l.fadeOut(now, cell.Lifecycle)
+			segments = append(segments, seg)
+		}
+	}
+	cell.Segments = segments
+	cell.dirty = true
+}
+
+// removeFaded drops the segments that have finished fading out
+func (cell *Cell) removeFaded(now time.Time) {
+	segments := cell.Segments[:0]
+	for _, seg := range cell.Segments {
+		if l, ok := seg.(lifecycler); ok && l.faded(now, cell.Lifecycle) {
+			continue
+		}
+		segments = append(segments, seg)
+	}
+	cell.Segments = segments
+}
+
+// morphPoints is the number of points each polygon is resampled to
+// when morphing
+const morphPoints = 96
+
+// outlineMorph interpolates the outline of one letter into another.
+// It is drawn in place of a cell's segments while the cell is
+// morphing.
+type outlineMorph struct {
+	segment
+	// pairs holds the matching polygons of the two outlines, with the
+	// same number of points in the same order
+	pairs [][2]contour
+	// to is the letter being morphed into
+	to Letter
+	// progress is the progress the morph was last drawn at
+	progress float64
+	// above and below are how far the outlines reach outside of the
+	// cell
+	above, below float64
+}
+
+// newOutlineMorph creates a morph between two letters drawn in the
+// given cell
+func newOutlineMorph(from, to Letter, cell *Cell) *outlineMorph {
+	font := cell.Font
+	if font == nil {
+		font = DefaultFont
+	}
+	for _, seg := range to {
+		if r, ok := seg.(resizer); ok {
+			r.resize(font.Width, font.Height)
+		}
+	}
+	morph := &outlineMorph{
+		segment: segment{W: font.Width, H: font.Height},
+		to:      to,
+	}
+
+	fromContours := outline(from, cell)
+	toContours := outline(to, cell)
+
+	// pair up the polygons that are closest to each other
+	type candidate struct {
+		from, to int
+		distance float64
+	}
+	candidates := []candidate{}
+	for i, a := range fromContours {
+		for j, b := range toContours {
+			// holes only become other holes
+			if a.hole() != b.hole() {
+				continue
+			}
+			ca, cb := a.centroid(), b.centroid()
+			candidates = append(candidates, candidate{i, j, math.Hypot(ca[0]-cb[0], ca[1]-cb[1])})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+	fromPaired := make([]bool, len(fromContours))
+	toPaired := make([]bool, len(toContours))
+	for _, c := range candidates {
+		if fromPaired[c.from] || toPaired[c.to] {
+			continue
+		}
+		fromPaired[c.from], toPaired[c.to] = true, true
+		a := fromContours[c.from].resample(morphPoints)
+		b := toContours[c.to].resample(morphPoints)
+		morph.pairs = append(morph.pairs, [2]contour{a.alignTo(b), b})
+	}
+
+	// any polygons left over shrink away or grow from nothing
+	for i, c := range fromContours {
+		if !fromPaired[i] {
+			morph.pairs = append(morph.pairs, [2]contour{c.resample(morphPoints), point(c.centroid(), morphPoints)})
+		}
+	}
+	for i, c := range toContours {
+		if !toPaired[i] {
+			morph.pairs = append(morph.pairs, [2]contour{point(c.centroid(), morphPoints), c.resample(morphPoints)})
+		}
+	}
+
+	// the outlines in between are made of points between those of
+	// the two letters, so they reach no further than the letters do
+	for _, pair := range morph.pairs {
+		for _, c := range pair {
+			for _, p := range c {
+				morph.above = math.Max(morph.above, -p[1])
+				morph.below = math.Max(morph.below, p[1]-(font.Height-1))
+			}
+		}
+	}
+	return morph
+}
+
+// Draw draws the outline part way through the morph, at the cell's
+// MorphProgress
+func (morph *outlineMorph) Draw(gc CellDrawer, cell *Cell) bool {
+	progress := math.Max(0, math.Min(1, cell.MorphProgress))
+	changed := progress != morph.progress
+	morph.progress = progress
+	gc.SetFillColor(cell.LetterColor)
+	// overlapping polygons from different segments must not cancel
+	// each other out
+	gc.SetFillRule(draw2d.FillRuleWinding)
+	for _, pair := range morph.pairs {
+		for i := range pair[0] {
+			x := pair[0][i][0] + (pair[1][i][0]-pair[0][i][0])*progress
+			y := pair[0][i][1] + (pair[1][i][1]-pair[0][i][1])*progress
+			if i == 0 {
+				gc.MoveTo(x, y)
+			} else {
+				gc.LineTo(x, y)
+			}
+		}
+		gc.Close()
+	}
+	gc.Fill()
+	gc.SetFillRule(draw2d.FillRuleEvenOdd)
+	return changed
+}
+
+// extent returns how far the outlines reach outside of the cell
+func (morph *outlineMorph) extent(style *Style) (above, below float64) {
+	return morph.above, morph.below
+}
+
+// ID returns the ID of the segment
+func (morph *outlineMorph) ID() SegmentID {
+	return IDMorph
+}
+
+// MorphTo starts smoothly changing the outline of the cell's current
+// letter into the given letter. While the cell is morphing, it is
+// drawn part way between the two at its MorphProgress, which goes
+// from 0 to 1, in place of its segments. EndMorph finishes the
+// morph.
+func (cell *Cell) MorphTo(letter Letter) {
+	from := Letter{}
+	for _, seg := range cell.Segments {
+		if l, ok := seg.(lifecycler); ok && l.fadingOut() {
+			continue
+		}
+		from = append(from, seg)
+	}
+	cell.morph = newOutlineMorph(from, letter, cell)
+	cell.MorphProgress = 0
+	cell.dirty = true
+}
+
+// Morphing returns whether the cell is morphing between two letters
+func (cell *Cell) Morphing() bool {
+	return cell.morph != nil
+}
+
+// EndMorph finishes morphing, leaving the cell showing the letter it
+// was morphing into
+func (cell *Cell) EndMorph() {
+	if cell.morph == nil {
+		return
+	}
+	now := cell.now()
+	for _, seg := range cell.morph.to {
+		if l, ok := seg.(lifecycler); ok {
+			l.show(now)
+		}
+	}
+	cell.Segments = cell.morph.to
+	cell.morph = nil
+	cell.MorphProgress = 0
+	cell.dirty = true
+}
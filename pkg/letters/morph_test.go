@@ -0,0 +1,235 @@
+package letters
+
+import (
+	"image"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/llgcode/draw2d/draw2dimg"
+)
+
+// fontCell creates a cell the size of the default font at the origin,
+// so that segment and cell coordinates are the same
+func fontCell(clock Clock) *Cell {
+	cell := NewCell([2]float64{0, 0}, [2]float64{DefaultFont.Width, DefaultFont.Height}, nil, ColorsDeath, ColorsParadox, DefaultFont)
+	cell.Clock = clock
+	return cell
+}
+
+// mustLetter returns the default font's letter for a character
+func mustLetter(t *testing.T, char string) Letter {
+	t.Helper()
+	phrase, _, err := DefaultFont.Letters(char, FallbackError)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return phrase[0]
+}
+
+// TestOutlineKeepsState checks that recording a letter's outline
+// doesn't move its segments through their lifecycle
+func TestOutlineKeepsState(t *testing.T) {
+	clock := NewManualClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	cell := fontCell(clock)
+	letter := mustLetter(t, "E")
+
+	// segments that have never been drawn haven't started fading in
+	outline(letter, cell)
+	for _, seg := range letter {
+		if state := seg.(stateSaver).saveState(); !state.stateStart.IsZero() || state.alpha != 0 {
+			t.Errorf("segment %d started fading in when its outline was recorded", seg.ID())
+		}
+	}
+
+	// and segments part way through fading in stay where they were
+	cell.SetLetter(letter)
+	cell.Paths()
+	clock.Advance(cell.Lifecycle.FadeIn / 4)
+	before := []segment{}
+	for _, seg := range letter {
+		before = append(before, seg.(stateSaver).saveState())
+	}
+	clock.Advance(cell.Lifecycle.FadeIn / 4)
+	outline(letter, cell)
+	for i, seg := range letter {
+		if state := seg.(stateSaver).saveState(); state != before[i] {
+			t.Errorf("segment %d went from %+v to %+v when its outline was recorded", seg.ID(), before[i], state)
+		}
+	}
+}
+
+// TestOutlineMorph checks that a morph starts as the outline of the
+// first letter and finishes as the outline of the second
+func TestOutlineMorph(t *testing.T) {
+	cell := fontCell(NewManualClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)))
+	from, to := mustLetter(t, "E"), mustLetter(t, "O")
+	morph := newOutlineMorph(from, to, cell)
+
+	if len(morph.pairs) == 0 {
+		t.Fatal("morph has no polygons")
+	}
+	for i, pair := range morph.pairs {
+		for j, c := range pair {
+			if len(c) != morphPoints {
+				t.Errorf("polygon %d has %d points at end %d, want %d", i, len(c), j, morphPoints)
+			}
+		}
+	}
+
+	// polygons that grow from nothing or shrink away are a single
+	// point at one end, so the rest are the polygons of each letter
+	for end, letter := range []Letter{from, to} {
+		shapes := 0
+		for _, pair := range morph.pairs {
+			if pair[end][0] != pair[end][len(pair[end])-1] || pair[end].area() != 0 {
+				shapes++
+			}
+		}
+		if want := len(outline(letter, cell)); shapes != want {
+			t.Errorf("morph has %d polygons at end %d, want %d", shapes, end, want)
+		}
+	}
+
+	for end, progress := range []float64{0, 1} {
+		cell.MorphProgress = progress
+		rec := newPathRecorder(cell, morph)
+		morph.Draw(rec, cell)
+		if len(rec.paths) != 1 {
+			t.Fatalf("progress %v: morph filled %d paths, want 1", progress, len(rec.paths))
+		}
+		points := rec.paths[0].Path.Points
+		want := []float64{}
+		for _, pair := range morph.pairs {
+			for _, p := range pair[end] {
+				want = append(want, p[0], p[1])
+			}
+		}
+		if len(points) != len(want) {
+			t.Errorf("progress %v: morph drew %d coordinates, want %d", progress, len(points), len(want))
+			continue
+		}
+		for i := range points {
+			if math.Abs(points[i]-want[i]) > 1e-9 {
+				t.Errorf("progress %v: coordinate %d is %v, want %v", progress, i, points[i], want[i])
+				break
+			}
+		}
+	}
+}
+
+// showLetter sets the letter of the cell and draws it until it is
+// solid
+func showLetter(cell *Cell, clock *ManualClock, letter Letter) {
+	cell.SetLetter(letter)
+	cell.Paths()
+	clock.Advance(cell.Lifecycle.FadeIn)
+	cell.Paths()
+}
+
+// segmentStates returns the state each of the cell's segments is in
+func segmentStates(cell *Cell) map[Segment]SegmentState {
+	states := map[Segment]SegmentState{}
+	for _, seg := range cell.Segments {
+		states[seg] = seg.(stateSaver).saveState().State
+	}
+	return states
+}
+
+func TestSetLetterKeepsShared(t *testing.T) {
+	clock := NewManualClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	cell := fontCell(clock)
+	e := mustLetter(t, "E")
+	showLetter(cell, clock, e)
+
+	// E and L share the bottom bar
+	l := mustLetter(t, "L")
+	cell.SetLetter(l)
+	cell.Paths()
+	states := segmentStates(cell)
+	for _, seg := range e {
+		state, ok := states[seg]
+		switch {
+		case !ok:
+			t.Errorf("segment %d of the E was dropped straight away", seg.ID())
+		case seg.ID() == IDELower && state != StateSolid:
+			t.Errorf("shared segment %d is in state %d, want it to stay solid", seg.ID(), state)
+		case seg.ID() != IDELower && state != StateFadeout:
+			t.Errorf("segment %d of the E is in state %d, want it to fade out", seg.ID(), state)
+		}
+	}
+	for _, seg := range l {
+		state, ok := states[seg]
+		if seg.ID() == IDELower {
+			// the E's bar is kept in place of the L's own
+			if ok {
+				t.Error("the L's own bottom bar was used in place of the E's")
+			}
+			continue
+		}
+		if !ok || state != StateFadein {
+			t.Errorf("segment %d of the L is in state %d, want it to fade in", seg.ID(), state)
+		}
+	}
+	if len(cell.Segments) != 4 {
+		t.Errorf("cell has %d segments, want 2 fading out, 1 kept and 1 fading in", len(cell.Segments))
+	}
+
+	// the segments that weren't kept are dropped once they've faded
+	clock.Advance(cell.Lifecycle.FadeOut)
+	cell.Draw(draw2dimg.NewGraphicContext(image.NewRGBA(image.Rect(0, 0, 1, 1))))
+	if got, want := segmentIDs([]Letter{cell.Segments}), [][]SegmentID{{IDNLeftVert, IDELower}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("cell has segments %v once faded, want %v", got, want)
+	}
+}
+
+func TestSetLetterRepeatedSegments(t *testing.T) {
+	clock := NewManualClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	cell := fontCell(clock)
+	first, second := &SegmentNBar{}, &SegmentNBar{}
+	showLetter(cell, clock, Letter{first, second})
+
+	// a letter using the same segment twice keeps both copies
+	cell.SetLetter(Letter{&SegmentNBar{}, &SegmentNBar{}})
+	if len(cell.Segments) != 2 || cell.Segments[0] != first || cell.Segments[1] != second {
+		t.Errorf("cell has segments %v, want both of %v", cell.Segments, Letter{first, second})
+	}
+
+	// and one copy is kept when only one is needed
+	cell.SetLetter(Letter{&SegmentNBar{}})
+	states := segmentStates(cell)
+	if states[first] != StateSolid || states[second] != StateFadeout {
+		t.Errorf("segments are in states %d and %d, want the first kept and the second fading out", states[first], states[second])
+	}
+}
+
+func TestSetLetterIgnoresFading(t *testing.T) {
+	clock := NewManualClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	cell := fontCell(clock)
+	e := mustLetter(t, "E")
+	showLetter(cell, clock, e)
+	cell.SetLetter(mustLetter(t, "L"))
+	cell.Paths()
+	clock.Advance(cell.Lifecycle.FadeOut / 2)
+
+	// changing back part way through the fade doesn't pick up the
+	// segments that are fading out, which fade in again as new ones
+	again := mustLetter(t, "E")
+	cell.SetLetter(again)
+	states := segmentStates(cell)
+	for i, seg := range e {
+		if seg.ID() == IDELower {
+			if states[seg] != StateSolid {
+				t.Errorf("kept segment %d is in state %d, want it to stay solid", seg.ID(), states[seg])
+			}
+			continue
+		}
+		if states[seg] != StateFadeout {
+			t.Errorf("fading segment %d is in state %d, want it to keep fading out", seg.ID(), states[seg])
+		}
+		if _, ok := states[again[i]]; !ok {
+			t.Errorf("segment %d of the new E is missing", seg.ID())
+		}
+	}
+}
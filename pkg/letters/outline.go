@@ -0,0 +1,229 @@
+package letters
+
+import (
+	"math"
+
+	"github.com/llgcode/draw2d/draw2dbase"
+)
+
+// outlineFlatness is the scale given to draw2d when flattening the
+// curves in an outline into lines
+const outlineFlatness = 4
+
+// contour is a closed polygon in an outline. Outer edges go
+// clockwise on screen and holes go anticlockwise, so that the outline
+// can be filled with the non-zero winding rule.
+type contour [][2]float64
+
+// contourFlattener collects the polygons of a flattened path
+type contourFlattener struct {
+	contours []contour
+	current  contour
+}
+
+// MoveTo starts a new polygon
+func (f *contourFlattener) MoveTo(x, y float64) {
+	f.End()
+	f.current = contour{{x, y}}
+}
+
+// LineTo adds a point to the polygon
+func (f *contourFlattener) LineTo(x, y float64) {
+	f.current = append(f.current, [2]float64{x, y})
+}
+
+// LineJoin does nothing, as joins only matter for strokes
+func (f *contourFlattener) LineJoin() {}
+
+// Close finishes the polygon
+func (f *contourFlattener) Close() {
+	f.End()
+}
+
+// End finishes the polygon, dropping it if it has no area
+func (f *contourFlattener) End() {
+	c := f.current
+	f.current = nil
+	// the path may have returned to its start before closing
+	if len(c) > 1 && c[0] == c[len(c)-1] {
+		c = c[:len(c)-1]
+	}
+	if len(c) < 3 {
+		return
+	}
+	f.contours = append(f.contours, c)
+}
+
+// outline returns the polygons that make up a letter when drawn in a
+// cell the size of the font, using the style of the given cell. The
+// segments are left in the state they were in.
+func outline(letter Letter, cell *Cell) []contour {
+	font := cell.Font
+	if font == nil {
+		font = DefaultFont
+	}
+	// draw at the origin, at the size the segments are designed for
+	recordCell := *cell
+	recordCell.TopLeft = [2]float64{0, 0}
+	recordCell.BottomRight = [2]float64{font.Width, font.Height}
+	if recordCell.LetterColor == nil {
+		recordCell.LetterColor = cell.DeathColors[1]
+	}
+
+	contours := []contour{}
+	for _, seg := range letter {
+		rec := newPathRecorder(&recordCell, seg)
+		// recording the segment isn't showing it, so it mustn't start
+		// fading in or move on through its fade
+		if saver, ok := seg.(stateSaver); ok {
+			state := saver.saveState()
+			seg.Draw(rec, &recordCell)
+			saver.restoreState(state)
+		} else {
+			seg.Draw(rec, &recordCell)
+		}
+		for _, path := range rec.paths {
+			flattener := &contourFlattener{}
+			draw2dbase.Flatten(path.Path, flattener, outlineFlatness)
+			flattener.End()
+			contours = append(contours, windContours(flattener.contours)...)
+		}
+	}
+	return contours
+}
+
+// windContours orients the polygons of a single fill, so that those
+// that are holes under the even-odd rule go anticlockwise and the
+// rest go clockwise
+func windContours(contours []contour) []contour {
+	wound := make([]contour, len(contours))
+	for i, c := range contours {
+		depth := 0
+		for j, other := range contours {
+			if i != j && other.contains(c[0]) {
+				depth++
+			}
+		}
+		hole := depth%2 == 1
+		if (c.area() < 0) != hole {
+			c = c.reversed()
+		}
+		wound[i] = c
+	}
+	return wound
+}
+
+// contains returns whether the point is inside the polygon
+func (c contour) contains(p [2]float64) bool {
+	inside := false
+	for i := range c {
+		a, b := c[i], c[(i+1)%len(c)]
+		if (a[1] > p[1]) != (b[1] > p[1]) &&
+			p[0] < a[0]+(p[1]-a[1])*(b[0]-a[0])/(b[1]-a[1]) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// reversed returns the polygon with its points in the opposite order
+func (c contour) reversed() contour {
+	reversed := make(contour, len(c))
+	for i, p := range c {
+		reversed[len(c)-1-i] = p
+	}
+	return reversed
+}
+
+// hole returns whether the polygon is a hole
+func (c contour) hole() bool {
+	return c.area() < 0
+}
+
+// area returns the signed area of the polygon, which is positive if
+// it is clockwise on screen
+func (c contour) area() float64 {
+	area := 0.0
+	for i := range c {
+		j := (i + 1) % len(c)
+		area += c[i][0]*c[j][1] - c[j][0]*c[i][1]
+	}
+	return area / 2
+}
+
+// centroid returns the average of the polygon's points
+func (c contour) centroid() [2]float64 {
+	var centre [2]float64
+	for _, p := range c {
+		centre[0] += p[0]
+		centre[1] += p[1]
+	}
+	centre[0] /= float64(len(c))
+	centre[1] /= float64(len(c))
+	return centre
+}
+
+// resample returns a polygon of n points spaced evenly around the
+// perimeter of the polygon, going the same way round
+func (c contour) resample(n int) contour {
+	points := c
+
+	// the distance around the perimeter to each point
+	distances := make([]float64, len(points)+1)
+	for i := range points {
+		next := points[(i+1)%len(points)]
+		distances[i+1] = distances[i] + math.Hypot(next[0]-points[i][0], next[1]-points[i][1])
+	}
+	perimeter := distances[len(points)]
+
+	resampled := make(contour, n)
+	edge := 0
+	for i := range resampled {
+		target := perimeter * float64(i) / float64(n)
+		for edge < len(points)-1 && distances[edge+1] < target {
+			edge++
+		}
+		start, end := points[edge], points[(edge+1)%len(points)]
+		t := 0.0
+		if length := distances[edge+1] - distances[edge]; length > 0 {
+			t = (target - distances[edge]) / length
+		}
+		resampled[i] = [2]float64{
+			start[0] + (end[0]-start[0])*t,
+			start[1] + (end[1]-start[1])*t,
+		}
+	}
+	return resampled
+}
+
+// alignTo rotates the points of the polygon so that they are as close
+// as possible to the points of the other polygon, which must have the
+// same number of points
+func (c contour) alignTo(other contour) contour {
+	best, bestDistance := 0, math.Inf(1)
+	for shift := range c {
+		distance := 0.0
+		for i, p := range other {
+			q := c[(i+shift)%len(c)]
+			distance += (p[0]-q[0])*(p[0]-q[0]) + (p[1]-q[1])*(p[1]-q[1])
+		}
+		if distance < bestDistance {
+			best, bestDistance = shift, distance
+		}
+	}
+	aligned := make(contour, len(c))
+	for i := range aligned {
+		aligned[i] = c[(i+best)%len(c)]
+	}
+	return aligned
+}
+
+// point returns a polygon of n points all at the same place, for
+// growing a polygon from nothing or shrinking one away
+func point(p [2]float64, n int) contour {
+	c := make(contour, n)
+	for i := range c {
+		c[i] = p
+	}
+	return c
+}
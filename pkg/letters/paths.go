@@ -0,0 +1,143 @@
+package letters
+
+import (
+	"image/color"
+
+	"github.com/llgcode/draw2d"
+)
+
+// SegmentPath is a shape filled by one of a cell's segments
+type SegmentPath struct {
+	ID       SegmentID
+	Color    color.Color
+	FillRule draw2d.FillRule
+	// Path is in the same coordinates as the cell
+	Path *draw2d.Path
+}
+
+// Paths returns the shapes the cell's segments fill when it is drawn,
+// in the order they are drawn, instead of drawing them. This is for
+// outputs that keep each segment separate, such as SVG.
+func (cell *Cell) Paths() []SegmentPath {
+	colors, _ := cell.colorsAt(cell.now())
+	cell.LetterColor = colors[1]
+	segments := cell.Segments
+	if cell.morph != nil {
+		segments = []Segment{cell.morph}
+	}
+	paths := []SegmentPath{}
+	for _, seg := range segments {
+		rec := newPathRecorder(cell, seg)
+		seg.Draw(rec, cell)
+		paths = append(paths, rec.paths...)
+	}
+	return paths
+}
+
+// pathRecorder is a CellDrawer that records the paths filled by a
+// segment instead of drawing them
+type pathRecorder struct {
+	cellDrawer
+	id       SegmentID
+	color    color.Color
+	fillRule draw2d.FillRule
+	path     draw2d.Path
+	paths    []SegmentPath
+}
+
+// newPathRecorder creates a pathRecorder for a segment, with the same
+// conversion between coordinate systems as NewCellDrawer
+func newPathRecorder(cell *Cell, segment Segment) *pathRecorder {
+	return &pathRecorder{
+		cellDrawer: cellDrawer{
+			CellTopLeft:   cell.TopLeft,
+			CellWidth:     cell.Width(),
+			CellHeight:    cell.Height(),
+			SegmentWidth:  segment.Width(),
+			SegmentHeight: segment.Height(),
+		},
+		id:    segment.ID(),
+		color: color.Black,
+	}
+}
+
+// Fill records the current path, or the given paths
+func (rec *pathRecorder) Fill(paths ...*draw2d.Path) {
+	if len(paths) == 0 {
+		paths = []*draw2d.Path{&rec.path}
+	}
+	for _, path := range paths {
+		if len(path.Components) == 0 {
+			continue
+		}
+		rec.paths = append(rec.paths, SegmentPath{
+			ID:       rec.id,
+			Color:    rec.color,
+			FillRule: rec.fillRule,
+			Path:     path.Copy(),
+		})
+	}
+	rec.path.Clear()
+}
+
+// FillStroke records the paths that would be filled
+func (rec *pathRecorder) FillStroke(paths ...*draw2d.Path) {
+	rec.Fill(paths...)
+}
+
+// Stroke discards the current path, as segments are only filled
+func (rec *pathRecorder) Stroke(paths ...*draw2d.Path) {
+	rec.path.Clear()
+}
+
+// Close closes the current path, if one has been started
+func (rec *pathRecorder) Close() {
+	if len(rec.path.Components) > 0 {
+		rec.path.Close()
+	}
+}
+
+// SetFillRule sets the rule the following paths are filled with
+func (rec *pathRecorder) SetFillRule(f draw2d.FillRule) {
+	rec.fillRule = f
+}
+
+// SetFillColor sets the colour the following paths are filled with
+func (rec *pathRecorder) SetFillColor(c color.Color) {
+	rec.color = c
+}
+
+// SetStrokeColor does nothing, as strokes aren't recorded
+func (rec *pathRecorder) SetStrokeColor(c color.Color) {}
+
+// MoveTo starts a new polygon in the current path
+func (rec *pathRecorder) MoveTo(x, y float64) {
+	rec.path.MoveTo(rec.ConvertCoords(x, y))
+}
+
+// LineTo adds a line to the current path
+func (rec *pathRecorder) LineTo(x, y float64) {
+	rec.path.LineTo(rec.ConvertCoords(x, y))
+}
+
+// QuadCurveTo adds a quadratic curve to the current path
+func (rec *pathRecorder) QuadCurveTo(cx, cy, x, y float64) {
+	newCx, newCy := rec.ConvertCoords(cx, cy)
+	newX, newY := rec.ConvertCoords(x, y)
+	rec.path.QuadCurveTo(newCx, newCy, newX, newY)
+}
+
+// CubicCurveTo adds a cubic curve to the current path
+func (rec *pathRecorder) CubicCurveTo(cx1, cy1, cx2, cy2, x, y float64) {
+	newCx1, newCy1 := rec.ConvertCoords(cx1, cy1)
+	newCx2, newCy2 := rec.ConvertCoords(cx2, cy2)
+	newX, newY := rec.ConvertCoords(x, y)
+	rec.path.CubicCurveTo(newCx1, newCy1, newCx2, newCy2, newX, newY)
+}
+
+// ArcTo adds an arc to the current path
+func (rec *pathRecorder) ArcTo(cx, cy, rx, ry, startAngle, angle float64) {
+	newCx, newCy := rec.ConvertCoords(cx, cy)
+	newRx, newRy := rec.ConvertLengths(rx, ry)
+	rec.path.ArcTo(newCx, newCy, newRx, newRy, startAngle, angle)
+}
@@ -0,0 +1,511 @@
+package letters
+
+import "math"
+
+// punctuation is inherited from by the punctuation segments. They
+// are drawn on the same cell as the letters, but only the left hand
+// part of the cell is used, so they provide a narrower advance width.
+type punctuation struct {
+	segment
+}
+
+// AdvanceWidth returns the horizontal space taken up by the segment
+// when drawn in the style. The narrow punctuation marks are a single
+// stick wide.
+func (seg *punctuation) AdvanceWidth(font *Font, style *Style) float64 {
+	return style.StickThickness
+}
+
+// markWidth returns the width a punctuation mark given its own width
+// in the style is drawn in, which is never wider than the cell
+func markWidth(markWidth, cellWidth float64) float64 {
+	return math.Min(markWidth, cellWidth)
+}
+
+/* Full Stop */
+
+// SegmentPeriod is the dot at the bottom of a full stop, exclamation
+// mark or colon
+type SegmentPeriod struct {
+	punctuation
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentPeriod) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	height := seg.Height()
+	gc.MoveTo(0, height-1-style.StickThickness)
+	gc.LineTo(style.StickThickness, height-1-style.StickThickness)
+	gc.LineTo(style.StickThickness, height-1)
+	gc.LineTo(0, height-1)
+	gc.LineTo(0, height-1-style.StickThickness)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentPeriod) ID() SegmentID { return IDPeriod }
+
+// LetterPeriod returns all the segments for a full stop
+func LetterPeriod() Letter {
+	return Letter{&SegmentPeriod{}}
+}
+
+/* Exclamation Mark */
+
+// SegmentExclamationStem is the line above the dot of an exclamation
+// mark
+type SegmentExclamationStem struct {
+	punctuation
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentExclamationStem) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	height := seg.Height()
+	gc.MoveTo(0, 0)
+	gc.LineTo(style.StickThickness, 0)
+	gc.LineTo(style.StickThickness, height-1-style.StickThickness-style.PunctuationDotGap)
+	gc.LineTo(0, height-1-style.StickThickness-style.PunctuationDotGap)
+	gc.LineTo(0, 0)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentExclamationStem) ID() SegmentID { return IDExclamationStem }
+
+// LetterExclamation returns all the segments for an exclamation mark
+func LetterExclamation() Letter {
+	return Letter{&SegmentExclamationStem{}, &SegmentPeriod{}}
+}
+
+/* Question Mark */
+
+// SegmentQuestionStem is the hook of a question mark, which runs
+// along the top, down the right and bends back into the centre
+type SegmentQuestionStem struct {
+	punctuation
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentQuestionStem) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := markWidth(style.QuestionWidth, seg.Width()), seg.Height()
+	gc.MoveTo(0, 0)
+	gc.LineTo(width-1, 0)
+	gc.LineTo(width-1, height/2+style.StickThickness/2-1)
+	gc.LineTo(width/2-1+style.StickThickness/2, height/2+style.StickThickness/2-1)
+	gc.LineTo(width/2-1+style.StickThickness/2, height-1-style.StickThickness-style.PunctuationDotGap)
+	gc.LineTo(width/2-1-style.StickThickness/2, height-1-style.StickThickness-style.PunctuationDotGap)
+	gc.LineTo(width/2-1-style.StickThickness/2, height/2-style.StickThickness/2-1)
+	gc.LineTo(width-1-style.StickThickness, height/2-style.StickThickness/2-1)
+	gc.LineTo(width-1-style.StickThickness, style.StickThickness)
+	gc.LineTo(0, style.StickThickness)
+	gc.LineTo(0, 0)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentQuestionStem) ID() SegmentID { return IDQuestionStem }
+
+// AdvanceWidth returns the horizontal space taken up by the segment
+func (seg *SegmentQuestionStem) AdvanceWidth(font *Font, style *Style) float64 {
+	return markWidth(style.QuestionWidth, font.Width)
+}
+
+// SegmentQuestionDot is the dot at the bottom of a question mark
+type SegmentQuestionDot struct {
+	punctuation
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentQuestionDot) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	width, height := markWidth(style.QuestionWidth, seg.Width()), seg.Height()
+	gc.MoveTo(width/2-1-style.StickThickness/2, height-1-style.StickThickness)
+	gc.LineTo(width/2-1+style.StickThickness/2, height-1-style.StickThickness)
+	gc.LineTo(width/2-1+style.StickThickness/2, height-1)
+	gc.LineTo(width/2-1-style.StickThickness/2, height-1)
+	gc.LineTo(width/2-1-style.StickThickness/2, height-1-style.StickThickness)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentQuestionDot) ID() SegmentID { return IDQuestionDot }
+
+// AdvanceWidth returns the horizontal space taken up by the segment
+func (seg *SegmentQuestionDot) AdvanceWidth(font *Font, style *Style) float64 {
+	return markWidth(style.QuestionWidth, font.Width)
+}
+
+// LetterQuestion returns all the segments for a question mark
+func LetterQuestion() Letter {
+	return Letter{&SegmentQuestionStem{}, &SegmentQuestionDot{}}
+}
+
+/* Comma */
+
+// SegmentComma is a full stop with its bottom cut away into a tail
+type SegmentComma struct {
+	punctuation
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentComma) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	height := seg.Height()
+	gc.MoveTo(0, height-1-style.StickThickness-style.CommaTail)
+	gc.LineTo(style.StickThickness, height-1-style.StickThickness-style.CommaTail)
+	gc.LineTo(style.StickThickness, height-1-style.CommaTail)
+	gc.LineTo(0, height-1)
+	gc.LineTo(0, height-1-style.StickThickness-style.CommaTail)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentComma) ID() SegmentID { return IDComma }
+
+// LetterComma returns all the segments for a comma
+func LetterComma() Letter {
+	return Letter{&SegmentComma{}}
+}
+
+/* Apostrophe */
+
+// SegmentApostrophe is a short stick hanging from the top of the cell
+type SegmentApostrophe struct {
+	punctuation
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentApostrophe) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	gc.MoveTo(0, 0)
+	gc.LineTo(style.StickThickness, 0)
+	gc.LineTo(style.StickThickness, style.ApostropheLength-style.CommaTail)
+	gc.LineTo(0, style.ApostropheLength)
+	gc.LineTo(0, 0)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentApostrophe) ID() SegmentID { return IDApostrophe }
+
+// LetterApostrophe returns all the segments for an apostrophe
+func LetterApostrophe() Letter {
+	return Letter{&SegmentApostrophe{}}
+}
+
+/* Colon */
+
+// SegmentColonUpper is the upper dot of a colon
+type SegmentColonUpper struct {
+	punctuation
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentColonUpper) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	height := seg.Height()
+	gc.MoveTo(0, height-1-2*style.StickThickness-style.ColonDotSeparation)
+	gc.LineTo(style.StickThickness, height-1-2*style.StickThickness-style.ColonDotSeparation)
+	gc.LineTo(style.StickThickness, height-1-style.StickThickness-style.ColonDotSeparation)
+	gc.LineTo(0, height-1-style.StickThickness-style.ColonDotSeparation)
+	gc.LineTo(0, height-1-2*style.StickThickness-style.ColonDotSeparation)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentColonUpper) ID() SegmentID { return IDColonUpper }
+
+// LetterColon returns all the segments for a colon
+func LetterColon() Letter {
+	return Letter{&SegmentColonUpper{}, &SegmentPeriod{}}
+}
+
+/* Hyphen */
+
+// SegmentHyphen is a short bar across the middle of the cell
+type SegmentHyphen struct {
+	punctuation
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentHyphen) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	height := seg.Height()
+	gc.MoveTo(0, height/2-style.StickThickness/2-1)
+	gc.LineTo(style.HyphenWidth-1, height/2-style.StickThickness/2-1)
+	gc.LineTo(style.HyphenWidth-1, height/2+style.StickThickness/2-1)
+	gc.LineTo(0, height/2+style.StickThickness/2-1)
+	gc.LineTo(0, height/2-style.StickThickness/2-1)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentHyphen) ID() SegmentID { return IDHyphen }
+
+// AdvanceWidth returns the horizontal space taken up by the segment
+func (seg *SegmentHyphen) AdvanceWidth(font *Font, style *Style) float64 { return style.HyphenWidth }
+
+// LetterHyphen returns all the segments for a hyphen
+func LetterHyphen() Letter {
+	return Letter{&SegmentHyphen{}}
+}
+
+/* Slash */
+
+// SegmentSlash is a forward slash
+type SegmentSlash struct {
+	punctuation
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentSlash) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	height := seg.Height()
+	gc.MoveTo(0, height-1)
+	gc.LineTo(style.SlashWidth-1-style.StickThickness, 0)
+	gc.LineTo(style.SlashWidth-1, 0)
+	gc.LineTo(style.StickThickness, height-1)
+	gc.LineTo(0, height-1)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentSlash) ID() SegmentID { return IDSlash }
+
+// AdvanceWidth returns the horizontal space taken up by the segment
+func (seg *SegmentSlash) AdvanceWidth(font *Font, style *Style) float64 { return style.SlashWidth }
+
+// LetterSlash returns all the segments for a forward slash
+func LetterSlash() Letter {
+	return Letter{&SegmentSlash{}}
+}
+
+/* Ampersand */
+
+// The ampersand is drawn with strokes half as thick as the letters,
+// the same as the hash, as there isn't room in the cell for its
+// counters otherwise. It is a loop on top, whose sides cross over
+// below it: the right side runs down to the left into the lower bowl,
+// and the left side kicks out as the leg to the bottom right.
+
+// ampersandLoopBottom is where the loop on the top half of an
+// ampersand ends and its sides cross over
+func ampersandLoopBottom(height float64) float64 {
+	return height * 2 / 5
+}
+
+// SegmentAmpersandUpper is the loop on the top half of an ampersand
+type SegmentAmpersandUpper struct {
+	punctuation
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentAmpersandUpper) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	thickness := style.StickThickness / 2
+	width, height := markWidth(style.AmpersandWidth, seg.Width()), seg.Height()
+	left, right := style.AmpersandInset/2, width-1-style.AmpersandInset
+	bottom := ampersandLoopBottom(height)
+	gc.MoveTo(left, bottom)
+	gc.LineTo(left, 0)
+	gc.LineTo(right, 0)
+	gc.LineTo(right, bottom)
+	gc.LineTo(right-thickness, bottom)
+	gc.LineTo(right-thickness, thickness)
+	gc.LineTo(left+thickness, thickness)
+	gc.LineTo(left+thickness, bottom)
+	gc.LineTo(left, bottom)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentAmpersandUpper) ID() SegmentID { return IDAmpersandUpper }
+
+// AdvanceWidth returns the horizontal space taken up by the segment
+func (seg *SegmentAmpersandUpper) AdvanceWidth(font *Font, style *Style) float64 {
+	return markWidth(style.AmpersandWidth, font.Width)
+}
+
+// SegmentAmpersandLower is the bowl on the bottom half of an
+// ampersand, which runs down from the right of the loop, round the
+// bottom left, and along to the foot of the leg
+type SegmentAmpersandLower struct {
+	punctuation
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentAmpersandLower) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	thickness := style.StickThickness / 2
+	width, height := markWidth(style.AmpersandWidth, seg.Width()), seg.Height()
+	right := width - 1 - style.AmpersandInset
+	loopBottom := ampersandLoopBottom(height)
+	// the diagonal drops a stroke's thickness below the loop before
+	// it reaches the left hand side
+	bowlTop := loopBottom + thickness
+	gc.MoveTo(right, loopBottom-thickness)
+	gc.LineTo(right, loopBottom)
+	gc.LineTo(thickness, bowlTop+thickness)
+	gc.LineTo(thickness, height-1-thickness)
+	gc.LineTo(width-1-thickness, height-1-thickness)
+	gc.LineTo(width-1-thickness, height-1)
+	gc.LineTo(0, height-1)
+	gc.LineTo(0, bowlTop)
+	gc.LineTo(right, loopBottom-thickness)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentAmpersandLower) ID() SegmentID { return IDAmpersandLower }
+
+// AdvanceWidth returns the horizontal space taken up by the segment
+func (seg *SegmentAmpersandLower) AdvanceWidth(font *Font, style *Style) float64 {
+	return markWidth(style.AmpersandWidth, font.Width)
+}
+
+// SegmentAmpersandLeg is the diagonal kicking out from the left of
+// the loop of an ampersand to the bottom right
+type SegmentAmpersandLeg struct {
+	punctuation
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentAmpersandLeg) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	thickness := style.StickThickness / 2
+	width, height := markWidth(style.AmpersandWidth, seg.Width()), seg.Height()
+	left := style.AmpersandInset / 2
+	top := ampersandLoopBottom(height)
+	gc.MoveTo(left, top)
+	gc.LineTo(left+thickness, top)
+	gc.LineTo(width-1, height-1)
+	gc.LineTo(width-1-thickness, height-1)
+	gc.LineTo(left, top)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentAmpersandLeg) ID() SegmentID { return IDAmpersandLeg }
+
+// AdvanceWidth returns the horizontal space taken up by the segment
+func (seg *SegmentAmpersandLeg) AdvanceWidth(font *Font, style *Style) float64 {
+	return markWidth(style.AmpersandWidth, font.Width)
+}
+
+// LetterAmpersand returns all the segments for an ampersand
+func LetterAmpersand() Letter {
+	return Letter{&SegmentAmpersandUpper{}, &SegmentAmpersandLower{}, &SegmentAmpersandLeg{}}
+}
+
+/* Hash */
+
+// SegmentHashVerticals are the two upright lines of a hash
+type SegmentHashVerticals struct {
+	punctuation
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentHashVerticals) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	hashThickness := style.StickThickness / 2
+	width, height := markWidth(style.HashWidth, seg.Width()), seg.Height()
+	gc.MoveTo(style.HashInset, 0)
+	gc.LineTo(style.HashInset+hashThickness, 0)
+	gc.LineTo(style.HashInset+hashThickness, height-1)
+	gc.LineTo(style.HashInset, height-1)
+	gc.LineTo(style.HashInset, 0)
+	gc.MoveTo(width-1-style.HashInset-hashThickness, 0)
+	gc.LineTo(width-1-style.HashInset, 0)
+	gc.LineTo(width-1-style.HashInset, height-1)
+	gc.LineTo(width-1-style.HashInset-hashThickness, height-1)
+	gc.LineTo(width-1-style.HashInset-hashThickness, 0)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentHashVerticals) ID() SegmentID { return IDHashVerticals }
+
+// AdvanceWidth returns the horizontal space taken up by the segment
+func (seg *SegmentHashVerticals) AdvanceWidth(font *Font, style *Style) float64 {
+	return markWidth(style.HashWidth, font.Width)
+}
+
+// SegmentHashBars are the two horizontal lines of a hash
+type SegmentHashBars struct {
+	punctuation
+}
+
+// Draw defines the behaviour of the segment
+func (seg *SegmentHashBars) Draw(gc CellDrawer, cell *Cell) bool {
+	changed := seg.setFillColor(gc, cell)
+	style := &cell.Style
+	hashThickness := style.StickThickness / 2
+	width, height := markWidth(style.HashWidth, seg.Width()), seg.Height()
+	gc.MoveTo(0, height/3-hashThickness/2)
+	gc.LineTo(width-1, height/3-hashThickness/2)
+	gc.LineTo(width-1, height/3+hashThickness/2)
+	gc.LineTo(0, height/3+hashThickness/2)
+	gc.LineTo(0, height/3-hashThickness/2)
+	gc.MoveTo(0, 2*height/3-hashThickness/2)
+	gc.LineTo(width-1, 2*height/3-hashThickness/2)
+	gc.LineTo(width-1, 2*height/3+hashThickness/2)
+	gc.LineTo(0, 2*height/3+hashThickness/2)
+	gc.LineTo(0, 2*height/3-hashThickness/2)
+	gc.Fill()
+	gc.Close()
+	return changed
+}
+
+// ID returns the ID of the segment
+func (seg *SegmentHashBars) ID() SegmentID { return IDHashBars }
+
+// AdvanceWidth returns the horizontal space taken up by the segment
+func (seg *SegmentHashBars) AdvanceWidth(font *Font, style *Style) float64 {
+	return markWidth(style.HashWidth, font.Width)
+}
+
+// LetterHash returns all the segments for a hash
+func LetterHash() Letter {
+	return Letter{&SegmentHashVerticals{}, &SegmentHashBars{}}
+}
@@ -0,0 +1,103 @@
+package letters
+
+import "testing"
+
+func TestPunctuation(t *testing.T) {
+	checkGlyphs(t, []rune("!?.,':-/&#"))
+
+	// the loop and the bowl of the ampersand are open, either side of
+	// where its strokes cross
+	letterFunc, _ := DefaultFont.LookupLetter('&')
+	img, cellRect := drawGlyph(letterFunc(), DefaultFont, DefaultStyle)
+	for _, probe := range ampersandCounters(DefaultWidth, DefaultHeight, DefaultStyle) {
+		x := cellRect.Min.X + int(probe[0]*glyphScale+glyphScale/2)
+		y := cellRect.Min.Y + int(probe[1]*glyphScale+glyphScale/2)
+		if img.RGBAAt(x, y).A != 0 {
+			t.Errorf("& is filled at (%v, %v)", probe[0], probe[1])
+		}
+	}
+
+	// the marks narrower than a letter draw nothing past their
+	// advance width, allowing for the cell being stretched by a
+	// pixel and for antialiasing
+	for _, r := range "!?.,':-/&#" {
+		letterFunc, _ := DefaultFont.LookupLetter(r)
+		letter := letterFunc()
+		img, cellRect := drawGlyph(letter, DefaultFont, DefaultStyle)
+		right := cellRect.Min.X + int((DefaultFont.AdvanceWidth(letter)+1)*glyphScale)
+		past := 0
+		for y := cellRect.Min.Y; y < cellRect.Max.Y; y++ {
+			for x := right; x < cellRect.Max.X; x++ {
+				if img.RGBAAt(x, y).A != 0 {
+					past++
+				}
+			}
+		}
+		if past != 0 {
+			t.Errorf("'%c' draws %d pixels past its advance width", r, past)
+		}
+	}
+}
+
+// ampersandCounters returns probes for the middle of the loop and
+// the bowl of an ampersand in a cell w by h
+func ampersandCounters(w, h float64, style Style) [][2]float64 {
+	w = markWidth(style.AmpersandWidth, w)
+	thickness := style.StickThickness / 2
+	left, right := style.AmpersandInset/2, w-1-style.AmpersandInset
+	loopBottom := ampersandLoopBottom(h)
+	return [][2]float64{
+		{(left + right) / 2, (thickness + loopBottom) / 2},
+		// the bowl is a triangle between the left hand side, the
+		// bottom and the leg
+		{thickness + 4, h - 1 - thickness - 4},
+		{thickness + 4, (loopBottom+h)/2 + 4},
+	}
+}
+
+func TestPunctuationAdvanceWidth(t *testing.T) {
+	narrow := DefaultStyle
+	narrow.StickThickness = 10
+	narrow.HyphenWidth = 20
+	narrow.QuestionWidth = 30
+	narrow.AmpersandWidth = 36
+	narrow.HashWidth = 38
+	// marks are never wider than the cell
+	wide := DefaultStyle
+	wide.QuestionWidth = 60
+	wide.AmpersandWidth = 60
+	wide.HashWidth = 60
+	tests := []struct {
+		char  rune
+		style Style
+		want  float64
+	}{
+		{'.', DefaultStyle, DefaultStyle.StickThickness},
+		{'.', narrow, 10},
+		{'-', DefaultStyle, DefaultStyle.HyphenWidth},
+		{'-', narrow, 20},
+		{'/', narrow, DefaultStyle.SlashWidth},
+		{'?', DefaultStyle, DefaultStyle.QuestionWidth},
+		{'?', narrow, 30},
+		{'?', wide, DefaultWidth},
+		{'&', DefaultStyle, DefaultStyle.AmpersandWidth},
+		{'&', narrow, 36},
+		{'&', wide, DefaultWidth},
+		{'#', DefaultStyle, DefaultStyle.HashWidth},
+		{'#', narrow, 38},
+		{'#', wide, DefaultWidth},
+		{'A', narrow, DefaultWidth},
+	}
+	for _, test := range tests {
+		letterFunc, _ := DefaultFont.LookupLetter(test.char)
+		cell := NewCell([2]float64{0, 0}, [2]float64{DefaultWidth, DefaultHeight}, letterFunc(), ColorsDeath, ColorsParadox, DefaultFont)
+		cell.Style = test.style
+		// the cell's own style is used, not the font's
+		if got := cell.AdvanceWidth(); got != test.want {
+			t.Errorf("'%c' in a cell has advance width %v, want %v", test.char, got, test.want)
+		}
+		if got := DefaultFont.AdvanceWidthIn(letterFunc(), &test.style); got != test.want {
+			t.Errorf("'%c' has advance width %v in the style, want %v", test.char, got, test.want)
+		}
+	}
+}
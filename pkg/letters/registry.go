@@ -0,0 +1,155 @@
+package letters
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Errors returned when registering letters
+var (
+	ErrLetterRegistered    = errors.New("letter already registered")
+	ErrLetterNotRegistered = errors.New("letter not registered")
+	ErrNilLetter           = errors.New("letter function is nil")
+	ErrFallbackCycle       = errors.New("font falls back to itself")
+)
+
+// RegisterLetter adds a new letter to the font. An error wrapping
+// ErrLetterRegistered is returned if the rune already has a letter
+// in this font; use ReplaceLetter to override an existing letter.
+// Letters provided by the fallback font may be registered over.
+func (font *Font) RegisterLetter(r rune, f func() Letter) error {
+	if f == nil {
+		return fmt.Errorf("could not register '%s': %w", string(r), ErrNilLetter)
+	}
+	font.lock.Lock()
+	defer font.lock.Unlock()
+	if _, ok := font.glyphs[r]; ok {
+		return fmt.Errorf("could not register '%s': %w", string(r), ErrLetterRegistered)
+	}
+	if font.glyphs == nil {
+		font.glyphs = make(map[rune]func() Letter)
+	}
+	font.glyphs[r] = f
+	return nil
+}
+
+// ReplaceLetter sets the letter for a rune in the font, whether or
+// not it is already registered. The previous letter function is
+// returned, or nil if there wasn't one.
+func (font *Font) ReplaceLetter(r rune, f func() Letter) (func() Letter, error) {
+	if f == nil {
+		return nil, fmt.Errorf("could not replace '%s': %w", string(r), ErrNilLetter)
+	}
+	font.lock.Lock()
+	defer font.lock.Unlock()
+	previous := font.glyphs[r]
+	if font.glyphs == nil {
+		font.glyphs = make(map[rune]func() Letter)
+	}
+	font.glyphs[r] = f
+	return previous, nil
+}
+
+// UnregisterLetter removes a letter from the font. An error wrapping
+// ErrLetterNotRegistered is returned if the font had no letter of its
+// own for the rune.
+func (font *Font) UnregisterLetter(r rune) error {
+	font.lock.Lock()
+	defer font.lock.Unlock()
+	if _, ok := font.glyphs[r]; !ok {
+		return fmt.Errorf("could not unregister '%s': %w", string(r), ErrLetterNotRegistered)
+	}
+	delete(font.glyphs, r)
+	return nil
+}
+
+// Fallbacks returns the font followed by each of the fonts it falls
+// back to, in the order they are searched for letters. If one of them
+// falls back to a font already in the chain, an error wrapping
+// ErrFallbackCycle is returned along with the fonts before the cycle.
+func (font *Font) Fallbacks() ([]*Font, error) {
+	chain := []*Font{}
+	seen := make(map[*Font]bool)
+	for f := font; f != nil; f = f.Fallback {
+		if seen[f] {
+			return chain, fmt.Errorf("font '%s' falls back to '%s': %w", chain[len(chain)-1].Name, f.Name, ErrFallbackCycle)
+		}
+		seen[f] = true
+		chain = append(chain, f)
+	}
+	return chain, nil
+}
+
+// LookupLetter returns the function for the letter for a rune, and
+// whether there was one. If the font has no letter for the rune, its
+// fallback fonts are searched, each only once even if they fall back
+// to each other.
+func (font *Font) LookupLetter(r rune) (func() Letter, bool) {
+	chain, _ := font.Fallbacks()
+	for _, f := range chain {
+		f.lock.RLock()
+		letterFunc, ok := f.glyphs[r]
+		f.lock.RUnlock()
+		if ok {
+			return letterFunc, true
+		}
+	}
+	return nil, false
+}
+
+// LetterMap returns a copy of the map of runes to letter functions
+// for every letter the font can draw, including those provided by
+// its fallback fonts
+func (font *Font) LetterMap() map[rune]func() Letter {
+	newMap := make(map[rune]func() Letter)
+	chain, _ := font.Fallbacks()
+	// the fonts earlier in the chain take precedence, so are added
+	// last
+	for i := len(chain) - 1; i >= 0; i-- {
+		chain[i].lock.RLock()
+		for key, val := range chain[i].glyphs {
+			newMap[key] = val
+		}
+		chain[i].lock.RUnlock()
+	}
+	return newMap
+}
+
+// RegisteredLetters returns all of the runes the font can draw, in
+// ascending order
+func (font *Font) RegisteredLetters() []rune {
+	letterMap := font.LetterMap()
+	runes := make([]rune, 0, len(letterMap))
+	for r := range letterMap {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	return runes
+}
+
+// RegisterLetter adds a new letter to DefaultFont
+func RegisterLetter(r rune, f func() Letter) error {
+	return DefaultFont.RegisterLetter(r, f)
+}
+
+// ReplaceLetter sets the letter for a rune in DefaultFont
+func ReplaceLetter(r rune, f func() Letter) (func() Letter, error) {
+	return DefaultFont.ReplaceLetter(r, f)
+}
+
+// UnregisterLetter removes a letter from DefaultFont
+func UnregisterLetter(r rune) error {
+	return DefaultFont.UnregisterLetter(r)
+}
+
+// LookupLetter returns the function for a letter in DefaultFont
+func LookupLetter(r rune) (func() Letter, bool) {
+	return DefaultFont.LookupLetter(r)
+}
+
+// RegisteredLetters returns all of the runes DefaultFont can draw, in
+// ascending order
+func RegisteredLetters() []rune {
+	return DefaultFont.RegisteredLetters()
+}
@@ -0,0 +1,176 @@
+package letters
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestRegisterLetterFontLiteral(t *testing.T) {
+	font := &Font{Name: "literal", Width: DefaultWidth, Height: DefaultHeight}
+	if _, ok := font.LookupLetter('A'); ok {
+		t.Error("empty font has a letter for 'A'")
+	}
+	if err := font.UnregisterLetter('A'); !errors.Is(err, ErrLetterNotRegistered) {
+		t.Errorf("UnregisterLetter returned error %v, want %v", err, ErrLetterNotRegistered)
+	}
+	if err := font.RegisterLetter('A', LetterA); err != nil {
+		t.Fatalf("RegisterLetter returned error: %s", err)
+	}
+	if _, ok := font.LookupLetter('A'); !ok {
+		t.Error("registered letter 'A' is missing")
+	}
+
+	font = &Font{Name: "literal", Width: DefaultWidth, Height: DefaultHeight}
+	previous, err := font.ReplaceLetter('B', LetterB)
+	if err != nil {
+		t.Fatalf("ReplaceLetter returned error: %s", err)
+	}
+	if previous != nil {
+		t.Error("ReplaceLetter returned a previous letter for an empty font")
+	}
+	if runes := font.RegisteredLetters(); len(runes) != 1 || runes[0] != 'B' {
+		t.Errorf("font has letters %q, want only 'B'", runes)
+	}
+}
+
+func TestRegisterLetterConflict(t *testing.T) {
+	font := NewFont("conflict", DefaultWidth, DefaultHeight, DefaultStyle, DefaultFont)
+	// a letter from the fallback font can be registered over
+	if err := font.RegisterLetter('A', LetterB); err != nil {
+		t.Fatalf("RegisterLetter over a fallback letter returned error: %s", err)
+	}
+	if err := font.RegisterLetter('A', LetterC); !errors.Is(err, ErrLetterRegistered) {
+		t.Errorf("RegisterLetter for an existing rune returned error %v, want %v", err, ErrLetterRegistered)
+	}
+	if err := font.RegisterLetter('Z', nil); !errors.Is(err, ErrNilLetter) {
+		t.Errorf("RegisterLetter with a nil letter returned error %v, want %v", err, ErrNilLetter)
+	}
+	// the first registration is kept
+	letterFunc, _ := font.LookupLetter('A')
+	if got, want := segmentIDs([]Letter{letterFunc()}), segmentIDs([]Letter{LetterB()}); !reflect.DeepEqual(got, want) {
+		t.Errorf("'A' has segments %v, want %v", got, want)
+	}
+}
+
+func TestDefaultFontRegistry(t *testing.T) {
+	if err := RegisterLetter('A', LetterB); !errors.Is(err, ErrLetterRegistered) {
+		t.Errorf("RegisterLetter('A') returned error %v, want %v", err, ErrLetterRegistered)
+	}
+
+	previous, err := ReplaceLetter('A', LetterB)
+	if err != nil {
+		t.Fatalf("ReplaceLetter returned error: %s", err)
+	}
+	defer ReplaceLetter('A', previous)
+	if got, want := segmentIDs([]Letter{previous()}), segmentIDs([]Letter{LetterA()}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ReplaceLetter returned a previous letter with segments %v, want %v", got, want)
+	}
+	letterFunc, _ := LookupLetter('A')
+	if got, want := segmentIDs([]Letter{letterFunc()}), segmentIDs([]Letter{LetterB()}); !reflect.DeepEqual(got, want) {
+		t.Errorf("replaced 'A' has segments %v, want %v", got, want)
+	}
+
+	const house = '@'
+	if err := RegisterLetter(house, LetterO); err != nil {
+		t.Fatalf("RegisterLetter returned error: %s", err)
+	}
+	if _, ok := GetLetterMap()[house]; !ok {
+		t.Error("registered letter is missing from GetLetterMap")
+	}
+	if err := UnregisterLetter(house); err != nil {
+		t.Errorf("UnregisterLetter returned error: %s", err)
+	}
+	if _, ok := LookupLetter(house); ok {
+		t.Error("unregistered letter can still be looked up")
+	}
+	if err := UnregisterLetter(house); !errors.Is(err, ErrLetterNotRegistered) {
+		t.Errorf("UnregisterLetter for a missing rune returned error %v, want %v", err, ErrLetterNotRegistered)
+	}
+}
+
+func TestRegisteredLettersOrder(t *testing.T) {
+	fallback := NewFont("fallback", DefaultWidth, DefaultHeight, DefaultStyle, nil)
+	font := NewFont("ordered", DefaultWidth, DefaultHeight, DefaultStyle, fallback)
+	for _, r := range "ZÉA" {
+		font.RegisterLetter(r, LetterA)
+	}
+	for _, r := range "5A" {
+		fallback.RegisterLetter(r, LetterA)
+	}
+	// letters are listed once, in ascending order, including those
+	// from the fallback font
+	if got, want := font.RegisteredLetters(), []rune("5AZÉ"); !reflect.DeepEqual(got, want) {
+		t.Errorf("RegisteredLetters returned %q, want %q", got, want)
+	}
+	runes := RegisteredLetters()
+	if !sort.SliceIsSorted(runes, func(i, j int) bool { return runes[i] < runes[j] }) {
+		t.Errorf("RegisteredLetters for DefaultFont isn't in order: %q", runes)
+	}
+}
+
+func TestRegistryConcurrent(t *testing.T) {
+	// run with -race to check the registry is safe for concurrent use
+	font := NewFont("concurrent", DefaultWidth, DefaultHeight, DefaultStyle, DefaultFont)
+	const n = 64
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		r := rune(0xE000 + i)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := font.RegisterLetter(r, LetterA); err != nil {
+				t.Errorf("RegisterLetter returned error: %s", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			font.LookupLetter(r)
+			font.LookupLetter('A')
+			font.RegisteredLetters()
+		}()
+	}
+	wg.Wait()
+	for i := 0; i < n; i++ {
+		if _, ok := font.LookupLetter(rune(0xE000 + i)); !ok {
+			t.Errorf("letter %d is missing", i)
+		}
+	}
+}
+
+func TestFallbackCycle(t *testing.T) {
+	a := NewFont("a", DefaultWidth, DefaultHeight, DefaultStyle, nil)
+	b := NewFont("b", DefaultWidth, DefaultHeight, DefaultStyle, a)
+	a.Fallback = b
+	a.RegisterLetter('A', LetterA)
+	b.RegisterLetter('B', LetterB)
+
+	if _, err := a.Fallbacks(); !errors.Is(err, ErrFallbackCycle) {
+		t.Errorf("Fallbacks returned error %v, want %v", err, ErrFallbackCycle)
+	}
+	// lookups still finish, searching each font once
+	if _, ok := a.LookupLetter('B'); !ok {
+		t.Error("'B' from the fallback font is missing")
+	}
+	if _, ok := b.LookupLetter('C'); ok {
+		t.Error("'C' was found, but neither font has it")
+	}
+	if got, want := a.RegisteredLetters(), []rune("AB"); !reflect.DeepEqual(got, want) {
+		t.Errorf("RegisteredLetters returned %q, want %q", got, want)
+	}
+	if _, _, err := a.Letters("AB", FallbackError); !errors.Is(err, ErrFallbackCycle) {
+		t.Errorf("Letters returned error %v, want %v", err, ErrFallbackCycle)
+	}
+	if err := RegisterFont(a); !errors.Is(err, ErrFallbackCycle) {
+		t.Errorf("RegisterFont returned error %v, want %v", err, ErrFallbackCycle)
+	}
+
+	// a font can't fall back to itself either
+	self := NewFont("self", DefaultWidth, DefaultHeight, DefaultStyle, nil)
+	self.Fallback = self
+	if _, err := self.Fallbacks(); !errors.Is(err, ErrFallbackCycle) {
+		t.Errorf("Fallbacks for a font falling back to itself returned error %v, want %v", err, ErrFallbackCycle)
+	}
+}
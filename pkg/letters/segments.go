@@ -1,5 +1,10 @@
 package letters
 
+import (
+	"image/color"
+	"time"
+)
+
 // SegmentState is a type used for storing various internal states of the segments
 type SegmentState int
 
@@ -10,6 +15,22 @@ const (
 	StateFadeout
 )
 
+// Lifecycle holds how long a segment spends in each of its states. A
+// segment fades in, stays solid, and then fades out. If Solid is zero
+// the segment stays solid until it is told to fade out.
+type Lifecycle struct {
+	FadeIn  time.Duration
+	Solid   time.Duration
+	FadeOut time.Duration
+}
+
+// DefaultLifecycle is the Lifecycle given to new cells
+var DefaultLifecycle = Lifecycle{
+	FadeIn:  400 * time.Millisecond,
+	Solid:   0,
+	FadeOut: 400 * time.Millisecond,
+}
+
 // SegmentID is a unique type for unique Segment IDs
 type SegmentID int
 
@@ -28,6 +49,12 @@ type segment struct {
 	State SegmentState
 	W     float64
 	H     float64
+
+	// stateStart is when the current state began, which is zero
+	// until the segment is first drawn
+	stateStart time.Time
+	// alpha is the opacity the segment was last drawn with
+	alpha float64
 }
 
 // Width returns the Width of the segment
@@ -45,3 +72,129 @@ func (seg *segment) Height() float64 {
 	}
 	return seg.H
 }
+
+// resizer is implemented by segments that can have their reference
+// size set to match a font
+type resizer interface {
+	resize(width, height float64)
+}
+
+// resize sets the reference size of the segment, unless it already
+// has one
+func (seg *segment) resize(width, height float64) {
+	if seg.W <= 0 {
+		seg.W = width
+	}
+	if seg.H <= 0 {
+		seg.H = height
+	}
+}
+
+// extender is implemented by segments that draw outside of the cell,
+// such as diacritics. extent returns how far above the top edge and
+// below the bottom edge the segment reaches, in segment coordinates.
+type extender interface {
+	extent(style *Style) (above, below float64)
+}
+
+// advance moves the segment through its states, and returns the
+// opacity it should be drawn with and whether it is still changing
+func (seg *segment) advance(now time.Time, lifecycle Lifecycle) (float64, bool) {
+	if seg.stateStart.IsZero() {
+		seg.State = StateFadein
+		seg.stateStart = now
+	}
+	for {
+		elapsed := now.Sub(seg.stateStart)
+		switch seg.State {
+		case StateFadein:
+			if elapsed < lifecycle.FadeIn {
+				return float64(elapsed) / float64(lifecycle.FadeIn), true
+			}
+			seg.State = StateSolid
+			seg.stateStart = seg.stateStart.Add(lifecycle.FadeIn)
+		case StateSolid:
+			if lifecycle.Solid <= 0 || elapsed < lifecycle.Solid {
+				return 1, false
+			}
+			seg.State = StateFadeout
+			seg.stateStart = seg.stateStart.Add(lifecycle.Solid)
+		default:
+			if elapsed < lifecycle.FadeOut {
+				return 1 - float64(elapsed)/float64(lifecycle.FadeOut), true
+			}
+			return 0, false
+		}
+	}
+}
+
+// fadeOut starts the segment fading out from its current opacity, if
+// it isn't already
+func (seg *segment) fadeOut(now time.Time, lifecycle Lifecycle) {
+	if seg.State == StateFadeout {
+		return
+	}
+	seg.State = StateFadeout
+	// start part way through the fade so the opacity doesn't jump
+	seg.stateStart = now.Add(-time.Duration((1 - seg.alpha) * float64(lifecycle.FadeOut)))
+}
+
+// faded returns whether the segment has finished fading out
+func (seg *segment) faded(now time.Time, lifecycle Lifecycle) bool {
+	return seg.State == StateFadeout && now.Sub(seg.stateStart) >= lifecycle.FadeOut
+}
+
+// show makes the segment solid straight away
+func (seg *segment) show(now time.Time) {
+	seg.State = StateSolid
+	seg.stateStart = now
+	seg.alpha = 1
+}
+
+// fadingOut returns whether the segment has started fading out
+func (seg *segment) fadingOut() bool {
+	return seg.State == StateFadeout
+}
+
+// setFillColor advances the segment's state and sets the fill colour
+// to the cell's letter colour at the segment's current opacity. It
+// returns whether the segment is still changing.
+func (seg *segment) setFillColor(gc CellDrawer, cell *Cell) bool {
+	alpha, changed := seg.advance(cell.now(), cell.Lifecycle)
+	if alpha != seg.alpha {
+		changed = true
+	}
+	seg.alpha = alpha
+	// the fade is applied to the colour's own opacity, which has to
+	// be taken out of its premultiplied channels first
+	c := color.NRGBAModel.Convert(cell.LetterColor).(color.NRGBA)
+	c.A = uint8(alpha*float64(c.A) + 0.5)
+	gc.SetFillColor(c)
+	return changed
+}
+
+// stateSaver is implemented by segments whose state is advanced by
+// drawing them, so that they can be drawn without being shown
+type stateSaver interface {
+	saveState() segment
+	restoreState(state segment)
+}
+
+// saveState returns the segment's current state
+func (seg *segment) saveState() segment {
+	return *seg
+}
+
+// restoreState puts back a state returned by saveState
+func (seg *segment) restoreState(state segment) {
+	*seg = state
+}
+
+// lifecycler is implemented by segments that go through the fade in,
+// solid and fade out states
+type lifecycler interface {
+	fadeOut(now time.Time, lifecycle Lifecycle)
+	faded(now time.Time, lifecycle Lifecycle) bool
+	fadingOut() bool
+	show(now time.Time)
+}
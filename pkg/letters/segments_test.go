@@ -0,0 +1,136 @@
+package letters
+
+import (
+	"image/color"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSegmentLifecycle(t *testing.T) {
+	const (
+		advance = iota
+		fadeOut
+		show
+	)
+	type step struct {
+		at     time.Duration
+		action int
+		// state and faded are checked after every step, and alpha and
+		// changing after advancing
+		state    SegmentState
+		alpha    float64
+		changing bool
+		faded    bool
+	}
+	ms := time.Millisecond
+	withSolid := DefaultLifecycle
+	withSolid.Solid = 300 * ms
+	tests := []struct {
+		name      string
+		lifecycle Lifecycle
+		steps     []step
+	}{
+		{"default", DefaultLifecycle, []step{
+			{0, advance, StateFadein, 0, true, false},
+			{200 * ms, advance, StateFadein, 0.5, true, false},
+			{400 * ms, advance, StateSolid, 1, false, false},
+			// without a solid time the segment stays solid until it
+			// is told to fade out
+			{5 * time.Second, advance, StateSolid, 1, false, false},
+			{5 * time.Second, fadeOut, StateFadeout, 0, false, false},
+			{5*time.Second + 200*ms, advance, StateFadeout, 0.5, true, false},
+			// fading out again doesn't restart the fade
+			{5*time.Second + 200*ms, fadeOut, StateFadeout, 0, false, false},
+			{5*time.Second + 400*ms, advance, StateFadeout, 0, false, true},
+		}},
+		{"solid time", withSolid, []step{
+			{0, advance, StateFadein, 0, true, false},
+			{500 * ms, advance, StateSolid, 1, false, false},
+			{800 * ms, advance, StateFadeout, 0.75, true, false},
+			{1100 * ms, advance, StateFadeout, 0, false, true},
+		}},
+		{"skipped states", withSolid, []step{
+			{0, advance, StateFadein, 0, true, false},
+			// a late frame passes through every state it missed
+			{900 * ms, advance, StateFadeout, 0.5, true, false},
+		}},
+		{"fade out while fading in", DefaultLifecycle, []step{
+			{0, advance, StateFadein, 0, true, false},
+			{100 * ms, advance, StateFadein, 0.25, true, false},
+			// the fade out starts from the opacity it was drawn with
+			{100 * ms, fadeOut, StateFadeout, 0, false, false},
+			{100 * ms, advance, StateFadeout, 0.25, true, false},
+			{200 * ms, advance, StateFadeout, 0, false, true},
+		}},
+		{"never drawn", DefaultLifecycle, []step{
+			{0, fadeOut, StateFadeout, 0, false, true},
+		}},
+		{"shown", DefaultLifecycle, []step{
+			{0, advance, StateFadein, 0, true, false},
+			{100 * ms, show, StateSolid, 0, false, false},
+			{100 * ms, advance, StateSolid, 1, false, false},
+			{100 * ms, fadeOut, StateFadeout, 0, false, false},
+			{300 * ms, advance, StateFadeout, 0.5, true, false},
+		}},
+	}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, test := range tests {
+		seg := &segment{}
+		for i, s := range test.steps {
+			now := start.Add(s.at)
+			switch s.action {
+			case advance:
+				// as setFillColor does when the segment is drawn
+				alpha, changing := seg.advance(now, test.lifecycle)
+				seg.alpha = alpha
+				if math.Abs(alpha-s.alpha) > 1e-9 || changing != s.changing {
+					t.Errorf("%s: step %d: advance returned %v, %v, want %v, %v", test.name, i+1, alpha, changing, s.alpha, s.changing)
+				}
+			case fadeOut:
+				seg.fadeOut(now, test.lifecycle)
+			case show:
+				seg.show(now)
+			}
+			if seg.State != s.state {
+				t.Errorf("%s: step %d: segment is in state %d, want %d", test.name, i+1, seg.State, s.state)
+			}
+			if fadingOut := seg.fadingOut(); fadingOut != (s.state == StateFadeout) {
+				t.Errorf("%s: step %d: fadingOut returned %v", test.name, i+1, fadingOut)
+			}
+			if faded := seg.faded(now, test.lifecycle); faded != s.faded {
+				t.Errorf("%s: step %d: faded returned %v, want %v", test.name, i+1, faded, s.faded)
+			}
+		}
+	}
+}
+
+func TestSegmentFillColor(t *testing.T) {
+	start := time.Unix(0, 0)
+	tests := []struct {
+		name   string
+		letter color.Color
+		at     time.Duration
+		want   color.NRGBA
+	}{
+		{"opaque", color.RGBA{0x95, 0x97, 0x7e, 0xff}, 200 * time.Millisecond, color.NRGBA{0x95, 0x97, 0x7e, 0x80}},
+		{"opaque solid", color.RGBA{0x95, 0x97, 0x7e, 0xff}, time.Second, color.NRGBA{0x95, 0x97, 0x7e, 0xff}},
+		// the colour isn't darkened by its own opacity as it fades
+		{"translucent", color.NRGBA{0x80, 0x40, 0x20, 0x80}, 200 * time.Millisecond, color.NRGBA{0x80, 0x40, 0x20, 0x40}},
+		{"premultiplied", color.RGBA{0x40, 0x20, 0x10, 0x80}, time.Second, color.NRGBA{0x7f, 0x3f, 0x1f, 0x80}},
+	}
+	for _, test := range tests {
+		clock := NewManualClock(start)
+		seg := &SegmentNBar{}
+		cell := NewCell([2]float64{0, 0}, [2]float64{DefaultWidth, DefaultHeight}, Letter{seg}, ColorsDeath, ColorsParadox, DefaultFont)
+		cell.Clock = clock
+		cell.LetterColor = test.letter
+		rec := newPathRecorder(cell, seg)
+		seg.setFillColor(rec, cell)
+		clock.Advance(test.at)
+		seg.setFillColor(rec, cell)
+		if got := color.NRGBAModel.Convert(rec.color); got != test.want {
+			t.Errorf("%s: segment filled with %v, want %v", test.name, got, test.want)
+		}
+	}
+}
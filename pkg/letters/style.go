@@ -0,0 +1,218 @@
+package letters
+
+import "math"
+
+// Style holds the stroke geometry used by the segments when they
+// draw. Each cell has its own Style, which its segments read every
+// time they are drawn, so it can be changed at runtime.
+type Style struct {
+	// StickThickness is the width of the strokes
+	StickThickness float64
+
+	// SStickLength is the length of the sticks on the tops and
+	// bottoms of an S
+	SStickLength float64
+	// ABarTopFromBottom is how far the top of the line through the
+	// centre of an A is above the bottom of the letter. A V is an A
+	// upside down, so its arms bend this far below the top.
+	ABarTopFromBottom float64
+	// EPointDepth is how far in the dip on the left of an E goes in
+	EPointDepth float64
+	// EEdgeDepth is how far in the centre line of the E is from the
+	// right
+	EEdgeDepth float64
+	// IOverhang is how far the top bar of an I hangs over
+	IOverhang float64
+	// DGapThinnest and DDiagonalHeight are the sizes of a D
+	DGapThinnest    float64
+	DDiagonalHeight float64
+	// BChamfer is how far the corners of the bowls on a B are cut in
+	BChamfer float64
+	// GSpurDepth is how far the spur on a G reaches in from the
+	// right
+	GSpurDepth float64
+	// MVertexHeight is how far down the point in the middle of an M
+	// reaches
+	MVertexHeight float64
+	// WVertexHeight is how far up the point in the middle of a W
+	// reaches
+	WVertexHeight float64
+	// OneFlagLength and OneFlagDrop are the sizes of the flag on a 1
+	OneFlagLength float64
+	OneFlagDrop   float64
+
+	// Sizes of the dots and marks used for punctuation. The widths
+	// are the advance widths of the marks, which are drawn no wider
+	// than the cell.
+	PunctuationDotGap  float64
+	CommaTail          float64
+	ApostropheLength   float64
+	HyphenWidth        float64
+	SlashWidth         float64
+	QuestionWidth      float64
+	AmpersandWidth     float64
+	AmpersandInset     float64
+	HashWidth          float64
+	HashInset          float64
+	ColonDotSeparation float64
+
+	// Sizes of the diacritics. These are drawn outside of the cell,
+	// either above the top edge or below the bottom edge, so that
+	// the base letter can be left untouched.
+	AccentGap       float64
+	AccentHeight    float64
+	AccentThickness float64
+	AccentSlant     float64
+	CircumflexWidth float64
+	DiaeresisGap    float64
+	TildeWidth      float64
+	RingSize        float64
+	RingThickness   float64
+	CedillaDrop     float64
+	CedillaTail     float64
+}
+
+// Sizes of the original segments, from before they were held in a
+// Style. They are the sizes DefaultStyle draws with.
+const (
+	// SStickLength is the length of the sticks on the tops and
+	// bottoms of an S.
+	//
+	// Deprecated: Use Style.SStickLength.
+	SStickLength = 7
+	// ABarUpperHeight is the height of the line through the centre of
+	// an A, measured down from the top of the letter.
+	//
+	// Deprecated: Use Style.ABarTopFromBottom, which is measured up
+	// from the bottom of the letter instead.
+	ABarUpperHeight = DefaultHeight - 35
+	// EPointDepth is how far in the dip on the left of an E goes in.
+	//
+	// Deprecated: Use Style.EPointDepth.
+	EPointDepth = 13
+	// EEdgeDepth is how far in the centre line of the E is from the
+	// right.
+	//
+	// Deprecated: Use Style.EEdgeDepth.
+	EEdgeDepth = 5
+	// IOverhang is how far the top bar of an I hangs over.
+	//
+	// Deprecated: Use Style.IOverhang.
+	IOverhang = 7
+	// DGapThinnest is the narrowest gap between the stem and the
+	// curve of a D.
+	//
+	// Deprecated: Use Style.DGapThinnest.
+	DGapThinnest = 5
+	// DDiagonalHeight is the height of the corners of a D.
+	//
+	// Deprecated: Use Style.DDiagonalHeight.
+	DDiagonalHeight = DefaultHeight / 4
+)
+
+// Built in styles
+var (
+	// DefaultStyle is the style of the original segments
+	DefaultStyle = Style{
+		StickThickness: stickThickness,
+
+		SStickLength:      SStickLength,
+		ABarTopFromBottom: DefaultHeight - ABarUpperHeight,
+		EPointDepth:       EPointDepth,
+		EEdgeDepth:        EEdgeDepth,
+		IOverhang:         IOverhang,
+		DGapThinnest:      DGapThinnest,
+		DDiagonalHeight:   DDiagonalHeight,
+		BChamfer:          10,
+		GSpurDepth:        26,
+		MVertexHeight:     DefaultHeight / 2,
+		WVertexHeight:     DefaultHeight / 2,
+		OneFlagLength:     14,
+		OneFlagDrop:       10,
+
+		PunctuationDotGap:  8,
+		CommaTail:          8,
+		ApostropheLength:   30,
+		HyphenWidth:        35,
+		SlashWidth:         35,
+		QuestionWidth:      40,
+		AmpersandWidth:     46,
+		AmpersandInset:     10,
+		HashWidth:          44,
+		HashInset:          9,
+		ColonDotSeparation: 12,
+
+		AccentGap:       6,
+		AccentHeight:    14,
+		AccentThickness: 12,
+		AccentSlant:     6,
+		CircumflexWidth: 16,
+		DiaeresisGap:    8,
+		TildeWidth:      18,
+		RingSize:        16,
+		RingThickness:   4,
+		CedillaDrop:     14,
+		CedillaTail:     6,
+	}
+	// LightStyle has thinner strokes than DefaultStyle
+	LightStyle = DefaultStyle.WithWeight(12)
+	// RegularStyle is the same as DefaultStyle
+	RegularStyle = DefaultStyle
+	// BlackStyle has thicker strokes than DefaultStyle. It is as
+	// heavy as the strokes can be while the counters of letters
+	// DefaultWidth wide, such as the gap between the verticals of an
+	// M, stay open.
+	BlackStyle = DefaultStyle.WithWeight(20)
+)
+
+// WithWeight returns a copy of the style with a different stroke
+// thickness
+func (style Style) WithWeight(thickness float64) Style {
+	style.StickThickness = thickness
+	return style
+}
+
+// ScaledTo returns a copy of the style for letters width by height,
+// rather than DefaultWidth by DefaultHeight. The stroke thickness is
+// scaled with the width, but is kept light enough that the counters
+// are no narrower than BlackStyle leaves them at the default size:
+// those between two strokes across the letter, such as in an M, and
+// between three strokes down it, such as in a B.
+func (style Style) ScaledTo(width, height float64) Style {
+	thickness := style.StickThickness * width / DefaultWidth
+	heaviest := math.Min(
+		BlackStyle.StickThickness+(width-DefaultWidth)/2,
+		BlackStyle.StickThickness+(height-DefaultHeight)*2/3,
+	)
+	return style.WithWeight(math.Min(thickness, heaviest))
+}
+
+// accentBottom is the bottom of the diacritics drawn above a letter
+func (style *Style) accentBottom() float64 {
+	return -style.AccentGap
+}
+
+// accentTop is the top of the diacritics drawn above a letter
+func (style *Style) accentTop() float64 {
+	return -style.AccentGap - style.AccentHeight
+}
+
+// accentThickTop is the top of a diacritic which is a single accent
+// thickness tall
+func (style *Style) accentThickTop() float64 {
+	return style.accentBottom() - style.AccentThickness
+}
+
+// accentThickInner is the inside edge of the top of a diacritic
+// which reaches the full accent height
+func (style *Style) accentThickInner() float64 {
+	return style.accentTop() + style.AccentThickness
+}
+
+// diagonalOffset returns how much further along one edge of a
+// diagonal stroke has to start than the other, for a stroke that
+// rises by rise over run to be thickness thick
+func diagonalOffset(run, rise, thickness float64) float64 {
+	gradient := rise / run
+	return thickness*math.Hypot(1, gradient) - thickness*gradient
+}
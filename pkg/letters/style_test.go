@@ -0,0 +1,188 @@
+package letters
+
+import "testing"
+
+// minCounter is the smallest a counter can be across, in segment
+// coordinates, for it to still read as open
+const minCounter = 8
+
+// counter returns probes for the centre of a counter and the points
+// minCounter/2 away from it in each direction
+func counter(x, y float64) [][2]float64 {
+	const r = minCounter / 2
+	return [][2]float64{{x, y}, {x - r, y}, {x + r, y}, {x, y - r}, {x, y + r}}
+}
+
+func TestStyleCountersOpen(t *testing.T) {
+	// the fonts are drawn in their own style, and in each of the
+	// built in styles scaled to fit them, as snakerender does
+	fonts := []*Font{DefaultFont, HeavyFont, CondensedFont}
+	styles := map[string]Style{
+		"light":   LightStyle,
+		"regular": RegularStyle,
+		"black":   BlackStyle,
+	}
+	// each letter has points in segment coordinates that must be
+	// left as background, in the counters and gaps of the letter
+	tests := []struct {
+		char   rune
+		probes func(w, h float64, style Style) [][2]float64
+	}{
+		{'A', func(w, h float64, style Style) [][2]float64 {
+			barTop := h - style.ABarTopFromBottom
+			// above and below the bar, between the legs
+			return [][2]float64{{(w - 1) / 2, barTop - 2}, {(w - 1) / 2, h - 2}}
+		}},
+		{'K', func(w, h float64, style Style) [][2]float64 {
+			// between the arms
+			return [][2]float64{{w - 2, h/2 - 1}}
+		}},
+		{'S', func(w, h float64, style Style) [][2]float64 {
+			// under the stick on the right of the top bar, and over
+			// the stick on the left of the bottom bar
+			stick := style.StickThickness + style.SStickLength
+			return [][2]float64{{w - 2, stick + 2}, {1, h - stick - 3}}
+		}},
+		{'V', func(w, h float64, style Style) [][2]float64 {
+			// between the arms
+			return [][2]float64{{(w - 1) / 2, 2}}
+		}},
+		{'M', func(w, h float64, style Style) [][2]float64 {
+			// under the chevron, between the verticals
+			return counter((w-1)/2, (style.MVertexHeight+h)/2)
+		}},
+		{'W', func(w, h float64, style Style) [][2]float64 {
+			// over the chevron, between the verticals
+			return counter((w-1)/2, (h-1-style.WVertexHeight)/2)
+		}},
+		{'B', func(w, h float64, style Style) [][2]float64 {
+			return append(upperBowl(w, h, style), lowerBowl(w, h, style)...)
+		}},
+		{'8', func(w, h float64, style Style) [][2]float64 {
+			return append(upperBowl(w, h, style), lowerBowl(w, h, style)...)
+		}},
+		{'R', func(w, h float64, style Style) [][2]float64 {
+			// in the bowl, and between the stem and the leg
+			return append(upperBowl(w, h, style), [2]float64{(w - 1) / 2, h - 2})
+		}},
+		{'D', func(w, h float64, style Style) [][2]float64 {
+			// in the middle of the bowl
+			return counter((w-1)/2, h/2)
+		}},
+		{'O', func(w, h float64, style Style) [][2]float64 {
+			return counter((w-1)/2, h/2)
+		}},
+		{'C', func(w, h float64, style Style) [][2]float64 {
+			return counter((w-1)/2, h/2)
+		}},
+		{'G', func(w, h float64, style Style) [][2]float64 {
+			// in the mouth between the top bar and the spur
+			top, spur := style.StickThickness-1, h/2-style.StickThickness/2-1
+			return counter(w-1-style.StickThickness, (top+spur)/2)
+		}},
+		{'Q', func(w, h float64, style Style) [][2]float64 {
+			// in the middle of the bowl, above the tail
+			return counter((w-1)/2, h/2)
+		}},
+		{'0', func(w, h float64, style Style) [][2]float64 {
+			return counter((w-1)/2, h/2)
+		}},
+		{'4', func(w, h float64, style Style) [][2]float64 {
+			// between the verticals, above the bar
+			return upperBowl(w, h, style)
+		}},
+		{'6', func(w, h float64, style Style) [][2]float64 {
+			// in the bowl, and in the gap on the right above it
+			return append(lowerBowl(w, h, style), upperBowl(w, h, style)...)
+		}},
+		{'9', func(w, h float64, style Style) [][2]float64 {
+			// in the bowl, and in the gap on the left below it
+			return append(upperBowl(w, h, style), lowerBowl(w, h, style)...)
+		}},
+		{'&', ampersandCounters},
+	}
+
+	for _, font := range fonts {
+		fontStyles := map[string]Style{font.Name: font.Style}
+		for name, style := range styles {
+			fontStyles[name] = style.ScaledTo(font.Width, font.Height)
+		}
+		for name, style := range fontStyles {
+			for _, test := range tests {
+				letterFunc, _ := font.LookupLetter(test.char)
+				img, cellRect := drawGlyph(letterFunc(), font, style)
+				for _, probe := range test.probes(font.Width, font.Height, style) {
+					x := cellRect.Min.X + int(probe[0]*glyphScale+glyphScale/2)
+					y := cellRect.Min.Y + int(probe[1]*glyphScale+glyphScale/2)
+					if img.RGBAAt(x, y).A != 0 {
+						t.Errorf("%c in %s font, %s style (weight %.1f) is filled at (%v, %v)", test.char, font.Name, name, style.StickThickness, probe[0], probe[1])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestStyleScaledTo(t *testing.T) {
+	tests := []struct {
+		name          string
+		style         Style
+		width, height float64
+		want          float64
+	}{
+		{"default size", RegularStyle, DefaultWidth, DefaultHeight, RegularStyle.StickThickness},
+		{"black at the default size", BlackStyle, DefaultWidth, DefaultHeight, BlackStyle.StickThickness},
+		{"narrower", LightStyle, 34, DefaultHeight, 8},
+		// the counters of letters such as M limit narrower letters
+		{"narrower and heavier", RegularStyle, 34, DefaultHeight, BlackStyle.StickThickness - (DefaultWidth-34)/2},
+		// wider letters are no taller, so the counters of letters
+		// such as B limit the weight to BlackStyle's
+		{"wider", RegularStyle, 62, DefaultHeight, BlackStyle.StickThickness},
+		{"black wider", BlackStyle, HeavyFont.Width, HeavyFont.Height, BlackStyle.StickThickness},
+		{"black narrower", BlackStyle, CondensedFont.Width, CondensedFont.Height, BlackStyle.StickThickness - (DefaultWidth-CondensedFont.Width)/2},
+	}
+	for _, test := range tests {
+		if got := test.style.ScaledTo(test.width, test.height).StickThickness; got != test.want {
+			t.Errorf("%s: weight %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+// upperBowl returns probes for the counter between the top bar and
+// the middle bar of letters such as B
+func upperBowl(w, h float64, style Style) [][2]float64 {
+	top, bottom := style.StickThickness-1, h/2-style.StickThickness/2-1
+	return counter((w-1)/2, (top+bottom)/2)
+}
+
+// lowerBowl returns probes for the counter between the middle bar and
+// the bottom bar of letters such as B
+func lowerBowl(w, h float64, style Style) [][2]float64 {
+	top, bottom := h/2+style.StickThickness/2-1, h-1-style.StickThickness
+	return counter((w-1)/2, (top+bottom)/2)
+}
+
+func TestDeprecatedSizes(t *testing.T) {
+	// the sizes from before there were styles are those of
+	// DefaultStyle, measured the way they used to be
+	tests := []struct {
+		name       string
+		size, want float64
+	}{
+		{"SStickLength", SStickLength, DefaultStyle.SStickLength},
+		{"ABarUpperHeight", ABarUpperHeight, DefaultHeight - DefaultStyle.ABarTopFromBottom},
+		{"EPointDepth", EPointDepth, DefaultStyle.EPointDepth},
+		{"EEdgeDepth", EEdgeDepth, DefaultStyle.EEdgeDepth},
+		{"IOverhang", IOverhang, DefaultStyle.IOverhang},
+		{"DGapThinnest", DGapThinnest, DefaultStyle.DGapThinnest},
+		{"DDiagonalHeight", DDiagonalHeight, DefaultStyle.DDiagonalHeight},
+	}
+	for _, test := range tests {
+		if test.size != test.want {
+			t.Errorf("%s is %v, want %v", test.name, test.size, test.want)
+		}
+	}
+	if ABarUpperHeight != 50 {
+		t.Errorf("ABarUpperHeight is %v, want 50 down from the top", ABarUpperHeight)
+	}
+}
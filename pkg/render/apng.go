@@ -0,0 +1,174 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+
+	"github.com/joshbarrass/SnakeIsDead/pkg/timeline"
+)
+
+// pngSignature starts every PNG file
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// apngFrame is a frame of an animated PNG
+type apngFrame struct {
+	// data is the compressed image data, from the IDAT chunks of the
+	// frame encoded as a PNG
+	data []byte
+	// delay is how long the frame is shown for, in milliseconds
+	delay int
+}
+
+// WriteAPNG renders the keyframes as a looping animated PNG. Frames
+// that are the same as the one before are merged into it.
+func WriteAPNG(w io.Writer, keyframes []timeline.Keyframe, opts Options) error {
+	opts = opts.withDefaults()
+	encoder := &png.Encoder{}
+	var header []byte
+	frames := []*apngFrame{}
+	// delays are whole milliseconds, so the time of each frame is
+	// rounded to avoid drifting from the frame rate
+	frameIndex := 0
+	lastTime := 0
+	var buf bytes.Buffer
+	err := Frames(keyframes, opts, func(frame *image.RGBA, changed bool) error {
+		frameIndex++
+		now := int(float64(frameIndex)*1000/opts.FPS + 0.5)
+		delay := now - lastTime
+		lastTime = now
+		if !changed && len(frames) > 0 {
+			frames[len(frames)-1].delay += delay
+			return nil
+		}
+
+		buf.Reset()
+		if err := encoder.Encode(&buf, frame); err != nil {
+			return err
+		}
+		frameHeader, data, err := splitPNG(buf.Bytes())
+		if err != nil {
+			return err
+		}
+		// every frame has to be encoded the same way as the first
+		if header == nil {
+			header = frameHeader
+		} else if !bytes.Equal(header, frameHeader) {
+			return errors.New("frames were encoded with different headers")
+		}
+		frames = append(frames, &apngFrame{data: data, delay: delay})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return writeAPNG(w, header, frames)
+}
+
+// splitPNG returns the contents of the IHDR chunk of a PNG and its
+// image data
+func splitPNG(data []byte) (header, imageData []byte, err error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, nil, errors.New("not a PNG")
+	}
+	data = data[len(pngSignature):]
+	for len(data) >= 12 {
+		length := binary.BigEndian.Uint32(data[:4])
+		if uint64(len(data)) < 12+uint64(length) {
+			break
+		}
+		chunkType := string(data[4:8])
+		contents := data[8 : 8+length]
+		switch chunkType {
+		case "IHDR":
+			header = contents
+		case "IDAT":
+			imageData = append(imageData, contents...)
+		}
+		data = data[12+length:]
+	}
+	if header == nil || imageData == nil {
+		return nil, nil, errors.New("PNG is missing its header or image data")
+	}
+	return header, imageData, nil
+}
+
+// writeChunk writes a PNG chunk
+func writeChunk(w io.Writer, chunkType string, contents []byte) error {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(contents)))
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(chunkType))
+	crc.Write(contents)
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc.Sum32())
+	for _, b := range [][]byte{length[:], []byte(chunkType), contents, sum[:]} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeAPNG writes the frames of an animated PNG
+func writeAPNG(w io.Writer, header []byte, frames []*apngFrame) error {
+	if _, err := w.Write(pngSignature); err != nil {
+		return err
+	}
+	if err := writeChunk(w, "IHDR", header); err != nil {
+		return err
+	}
+	// the animation control chunk gives the number of frames, and
+	// loops forever
+	actl := make([]byte, 8)
+	binary.BigEndian.PutUint32(actl[0:], uint32(len(frames)))
+	binary.BigEndian.PutUint32(actl[4:], 0)
+	if err := writeChunk(w, "acTL", actl); err != nil {
+		return err
+	}
+
+	// fcTL and fdAT chunks share a sequence number
+	sequence := uint32(0)
+	width := binary.BigEndian.Uint32(header[0:])
+	height := binary.BigEndian.Uint32(header[4:])
+	for i, frame := range frames {
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:], sequence)
+		binary.BigEndian.PutUint32(fctl[4:], width)
+		binary.BigEndian.PutUint32(fctl[8:], height)
+		// the x and y offsets are left at zero
+		delay := frame.delay
+		if delay > 0xffff {
+			delay = 0xffff
+		}
+		binary.BigEndian.PutUint16(fctl[20:], uint16(delay))
+		binary.BigEndian.PutUint16(fctl[22:], 1000)
+		// the dispose and blend operations are left as none and
+		// source, as each frame is complete
+		if err := writeChunk(w, "fcTL", fctl); err != nil {
+			return err
+		}
+		sequence++
+
+		// the first frame is also the image shown by viewers that
+		// don't support animation
+		if i == 0 {
+			if err := writeChunk(w, "IDAT", frame.data); err != nil {
+				return err
+			}
+			continue
+		}
+		fdat := make([]byte, 4+len(frame.data))
+		binary.BigEndian.PutUint32(fdat, sequence)
+		copy(fdat[4:], frame.data)
+		if err := writeChunk(w, "fdAT", fdat); err != nil {
+			return err
+		}
+		sequence++
+	}
+	return writeChunk(w, "IEND", nil)
+}
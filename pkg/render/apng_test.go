@@ -0,0 +1,146 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// pngChunk is a chunk read back from a PNG
+type pngChunk struct {
+	chunkType string
+	contents  []byte
+}
+
+// readChunks splits a PNG into its chunks, checking their CRCs
+func readChunks(t *testing.T, data []byte) []pngChunk {
+	t.Helper()
+	if !bytes.HasPrefix(data, pngSignature) {
+		t.Fatal("output doesn't start with the PNG signature")
+	}
+	data = data[len(pngSignature):]
+	chunks := []pngChunk{}
+	for len(data) > 0 {
+		if len(data) < 12 {
+			t.Fatalf("%d bytes left over after the last chunk", len(data))
+		}
+		length := binary.BigEndian.Uint32(data)
+		if uint64(len(data)) < 12+uint64(length) {
+			t.Fatalf("chunk %s is truncated", data[4:8])
+		}
+		chunk := pngChunk{string(data[4:8]), data[8 : 8+length]}
+		if crc := crc32.ChecksumIEEE(data[4 : 8+length]); crc != binary.BigEndian.Uint32(data[8+length:]) {
+			t.Errorf("chunk %s has the wrong CRC", chunk.chunkType)
+		}
+		chunks = append(chunks, chunk)
+		data = data[12+length:]
+	}
+	return chunks
+}
+
+func TestWriteAPNG(t *testing.T) {
+	keyframes := parseKeyframes(t, `[
+		{"at": 0, "action": "phrase", "text": "AB"},
+		{"at": 1000, "action": "translate", "dx": 20, "over": 200}
+	]`)
+	opts := Options{FPS: 10, Duration: 2 * time.Second}
+
+	// work out the frames the animation should have from the frames
+	// rendered, merging those that don't change into the one before
+	var first *image.RGBA
+	wantDelays := []int{}
+	err := Frames(keyframes, opts, func(frame *image.RGBA, changed bool) error {
+		if first == nil {
+			first = image.NewRGBA(frame.Rect)
+			copy(first.Pix, frame.Pix)
+		}
+		if changed || len(wantDelays) == 0 {
+			wantDelays = append(wantDelays, 0)
+		}
+		wantDelays[len(wantDelays)-1] += 100
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wantDelays) < 3 {
+		t.Fatalf("animation only has %d frames, so doesn't test merging", len(wantDelays))
+	}
+
+	var buf bytes.Buffer
+	if err := WriteAPNG(&buf, keyframes, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	// viewers that don't support animation show the first frame
+	img, err := png.Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("could not decode output: %s", err)
+	}
+	if img.Bounds() != first.Rect {
+		t.Fatalf("decoded image is %v, want %v", img.Bounds(), first.Rect)
+	}
+	for y := first.Rect.Min.Y; y < first.Rect.Max.Y; y++ {
+		for x := first.Rect.Min.X; x < first.Rect.Max.X; x++ {
+			r1, g1, b1, a1 := img.At(x, y).RGBA()
+			r2, g2, b2, a2 := first.At(x, y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+				t.Fatalf("decoded image differs from the first frame at (%d, %d)", x, y)
+			}
+		}
+	}
+
+	chunks := readChunks(t, buf.Bytes())
+	types := []string{}
+	for _, chunk := range chunks {
+		types = append(types, chunk.chunkType)
+	}
+	wantTypes := []string{"IHDR", "acTL", "fcTL", "IDAT"}
+	for range wantDelays[1:] {
+		wantTypes = append(wantTypes, "fcTL", "fdAT")
+	}
+	wantTypes = append(wantTypes, "IEND")
+	if !reflect.DeepEqual(types, wantTypes) {
+		t.Fatalf("output has chunks %v, want %v", types, wantTypes)
+	}
+
+	actl := chunks[1].contents
+	if frames := binary.BigEndian.Uint32(actl); int(frames) != len(wantDelays) {
+		t.Errorf("acTL has %d frames, want %d", frames, len(wantDelays))
+	}
+	if plays := binary.BigEndian.Uint32(actl[4:]); plays != 0 {
+		t.Errorf("acTL plays %d times, want 0 to loop forever", plays)
+	}
+
+	sequence := uint32(0)
+	delays := []int{}
+	for _, chunk := range chunks {
+		switch chunk.chunkType {
+		case "fcTL", "fdAT":
+			if got := binary.BigEndian.Uint32(chunk.contents); got != sequence {
+				t.Errorf("%s has sequence number %d, want %d", chunk.chunkType, got, sequence)
+			}
+			sequence++
+		}
+		if chunk.chunkType == "fcTL" {
+			num := binary.BigEndian.Uint16(chunk.contents[20:])
+			den := binary.BigEndian.Uint16(chunk.contents[22:])
+			delays = append(delays, int(num)*1000/int(den))
+		}
+	}
+	if !reflect.DeepEqual(delays, wantDelays) {
+		t.Errorf("frames have delays %v, want %v", delays, wantDelays)
+	}
+	total := 0
+	for _, delay := range delays {
+		total += delay
+	}
+	if total != int(opts.Duration/time.Millisecond) {
+		t.Errorf("frames last %dms in total, want %v", total, opts.Duration)
+	}
+}
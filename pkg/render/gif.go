@@ -0,0 +1,115 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+
+	"github.com/joshbarrass/SnakeIsDead/pkg/letters"
+	"github.com/joshbarrass/SnakeIsDead/pkg/timeline"
+)
+
+// Palette returns a palette holding the given colours and the colours
+// part way between each pair of them, so that fades and changes of
+// colour mode can be drawn without dithering
+func Palette(colors ...color.RGBA) color.Palette {
+	pairs := len(colors) * (len(colors) - 1) / 2
+	steps := 256
+	if pairs > 0 {
+		steps = (256 - len(colors)) / pairs
+	}
+	seen := map[color.RGBA]bool{}
+	palette := color.Palette{}
+	add := func(c color.RGBA) {
+		if !seen[c] && len(palette) < 256 {
+			seen[c] = true
+			palette = append(palette, c)
+		}
+	}
+	for _, c := range colors {
+		add(c)
+	}
+	for i, a := range colors {
+		for _, b := range colors[i+1:] {
+			for step := 1; step <= steps; step++ {
+				t := float64(step) / float64(steps+1)
+				add(color.RGBA{
+					uint8(float64(a.R) + (float64(b.R)-float64(a.R))*t + 0.5),
+					uint8(float64(a.G) + (float64(b.G)-float64(a.G))*t + 0.5),
+					uint8(float64(a.B) + (float64(b.B)-float64(a.B))*t + 0.5),
+					0xff,
+				})
+			}
+		}
+	}
+	return palette
+}
+
+// DefaultPalette is the palette used for GIFs, made from the Death
+// and Paradox colours
+var DefaultPalette = Palette(
+	letters.ColorsDeath[0],
+	letters.ColorsDeath[1],
+	letters.ColorsParadox[0],
+	letters.ColorsParadox[1],
+)
+
+// keyframesPalette returns the palette for a GIF of the keyframes.
+// This is DefaultPalette, unless the keyframes change the colours of
+// the cells, in which case it is made from the colours they use.
+func keyframesPalette(keyframes []timeline.Keyframe) color.Palette {
+	colors := []color.RGBA{}
+	for _, kf := range keyframes {
+		if c, ok := kf.Action.(*timeline.Colors); ok {
+			colors = append(colors, c.Death[0], c.Death[1], c.Paradox[0], c.Paradox[1])
+		}
+	}
+	if len(colors) == 0 {
+		return DefaultPalette
+	}
+	// drop repeated colours, so they don't take space from the fades
+	unique := []color.RGBA{}
+	seen := map[color.RGBA]bool{}
+	for _, c := range colors {
+		if !seen[c] {
+			seen[c] = true
+			unique = append(unique, c)
+		}
+	}
+	return Palette(unique...)
+}
+
+// WriteGIF renders the keyframes as a looping animated GIF. Frames
+// that are the same as the one before are merged into it.
+func WriteGIF(w io.Writer, keyframes []timeline.Keyframe, opts Options) error {
+	opts = opts.withDefaults()
+	anim := &gif.GIF{
+		LoopCount: 0,
+	}
+	// GIF delays are in hundredths of a second, so the time of each
+	// frame is rounded to avoid drifting from the frame rate
+	palette := keyframesPalette(keyframes)
+	frameIndex := 0
+	lastTime := 0
+	err := Frames(keyframes, opts, func(frame *image.RGBA, changed bool) error {
+		frameIndex++
+		now := int(float64(frameIndex)*100/opts.FPS + 0.5)
+		delay := now - lastTime
+		lastTime = now
+		if !changed && len(anim.Image) > 0 {
+			anim.Delay[len(anim.Delay)-1] += delay
+			return nil
+		}
+		paletted := image.NewPaletted(frame.Bounds(), palette)
+		draw.Draw(paletted, frame.Bounds(), frame, image.Point{}, draw.Src)
+		anim.Image = append(anim.Image, paletted)
+		anim.Delay = append(anim.Delay, delay)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return gif.EncodeAll(w, anim)
+}
@@ -0,0 +1,151 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joshbarrass/SnakeIsDead/pkg/letters"
+	"github.com/joshbarrass/SnakeIsDead/pkg/timeline"
+)
+
+// hasColor returns whether the palette holds exactly the given colour
+func hasColor(palette color.Palette, c color.RGBA) bool {
+	for _, p := range palette {
+		if p == color.Color(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestWriteGIF(t *testing.T) {
+	keyframes := parseKeyframes(t, `[
+		{"at": 0, "action": "phrase", "text": "AB"},
+		{"at": 1000, "action": "translate", "dx": 20, "over": 200}
+	]`)
+	opts := Options{FPS: 10, Duration: 2 * time.Second}
+
+	// work out the frames the animation should have from the frames
+	// rendered, merging those that don't change into the one before
+	var bounds image.Rectangle
+	wantDelays := []int{}
+	err := Frames(keyframes, opts, func(frame *image.RGBA, changed bool) error {
+		bounds = frame.Rect
+		if changed || len(wantDelays) == 0 {
+			wantDelays = append(wantDelays, 0)
+		}
+		wantDelays[len(wantDelays)-1] += 10
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the 20 frames rendered are merged into the 6 of the fade in and
+	// the 2 of the move, each of which lasts until the next change
+	if len(wantDelays) != 8 {
+		t.Fatalf("animation has %d frames with delays %v, want 8", len(wantDelays), wantDelays)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteGIF(&buf, keyframes, opts); err != nil {
+		t.Fatal(err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("could not decode output: %s", err)
+	}
+
+	if anim.LoopCount != 0 {
+		t.Errorf("GIF has loop count %d, want 0 to loop forever", anim.LoopCount)
+	}
+	if len(anim.Image) != len(wantDelays) {
+		t.Fatalf("GIF has %d frames, want %d", len(anim.Image), len(wantDelays))
+	}
+	if !reflect.DeepEqual(anim.Delay, wantDelays) {
+		t.Errorf("frames have delays %v, want %v", anim.Delay, wantDelays)
+	}
+	total := 0
+	for _, delay := range anim.Delay {
+		total += delay
+	}
+	if total != int(opts.Duration/(10*time.Millisecond)) {
+		t.Errorf("frames last %d hundredths of a second in total, want %v", total, opts.Duration)
+	}
+	for i, frame := range anim.Image {
+		if frame.Rect != bounds {
+			t.Errorf("frame %d is %v, want %v", i, frame.Rect, bounds)
+		}
+		// the encoder pads the palette out to a power of two
+		if len(frame.Palette) < len(DefaultPalette) || !reflect.DeepEqual(frame.Palette[:len(DefaultPalette)], DefaultPalette) {
+			t.Errorf("frame %d doesn't use DefaultPalette", i)
+		}
+	}
+}
+
+func TestWriteGIFDelaysDontDrift(t *testing.T) {
+	// at 30fps a frame lasts 3⅓ hundredths of a second, so the delays
+	// have to be rounded differently from frame to frame to add up
+	keyframes := parseKeyframes(t, `[{"at": 0, "action": "phrase", "text": "A"}]`)
+	opts := Options{FPS: 30, Duration: time.Second}
+	var buf bytes.Buffer
+	if err := WriteGIF(&buf, keyframes, opts); err != nil {
+		t.Fatal(err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("could not decode output: %s", err)
+	}
+	total := 0
+	for _, delay := range anim.Delay {
+		total += delay
+	}
+	if total != 100 {
+		t.Errorf("frames last %d hundredths of a second in total, want 100", total)
+	}
+}
+
+func TestDefaultPalette(t *testing.T) {
+	if len(DefaultPalette) > 256 {
+		t.Fatalf("DefaultPalette has %d colours, more than a GIF can hold", len(DefaultPalette))
+	}
+	for _, c := range []color.RGBA{
+		letters.ColorsDeath[0], letters.ColorsDeath[1],
+		letters.ColorsParadox[0], letters.ColorsParadox[1],
+	} {
+		if !hasColor(DefaultPalette, c) {
+			t.Errorf("DefaultPalette doesn't hold %v", c)
+		}
+	}
+}
+
+func TestKeyframesPalette(t *testing.T) {
+	keyframes := parseKeyframes(t, `[{"at": 0, "action": "phrase", "text": "AB"}]`)
+	if got := keyframesPalette(keyframes); !reflect.DeepEqual(got, DefaultPalette) {
+		t.Error("keyframes without a colors action don't use DefaultPalette")
+	}
+
+	colors := &timeline.Colors{
+		Death:   [2]color.RGBA{{0x10, 0x20, 0x30, 0xff}, {0xf0, 0xe0, 0xd0, 0xff}},
+		Paradox: [2]color.RGBA{{0x10, 0x20, 0x30, 0xff}, {0x80, 0x00, 0x80, 0xff}},
+	}
+	keyframes = append([]timeline.Keyframe{{Action: colors}}, keyframes...)
+	palette := keyframesPalette(keyframes)
+	if len(palette) > 256 {
+		t.Fatalf("palette has %d colours, more than a GIF can hold", len(palette))
+	}
+	for _, c := range append(colors.Death[:], colors.Paradox[:]...) {
+		if !hasColor(palette, c) {
+			t.Errorf("palette doesn't hold %v from the colors action", c)
+		}
+	}
+	// the colour used twice only takes one place, leaving the rest of
+	// the palette for the fades between the three colours
+	if want := Palette(colors.Death[0], colors.Death[1], colors.Paradox[1]); !reflect.DeepEqual(palette, want) {
+		t.Error("palette isn't made from the unique colours of the colors action")
+	}
+}
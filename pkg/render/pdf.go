@@ -0,0 +1,201 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/joshbarrass/SnakeIsDead/pkg/timeline"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/llgcode/draw2d/draw2dkit"
+	"github.com/llgcode/draw2d/draw2dpdf"
+)
+
+// Inch is the number of millimetres in an inch, for giving poster
+// sizes in inches
+const Inch = 25.4
+
+// PageSize is the size of a sheet of paper in millimetres, in portrait
+type PageSize struct {
+	Width, Height float64
+}
+
+// Common paper sizes
+var (
+	PageA4     = PageSize{210, 297}
+	PageA3     = PageSize{297, 420}
+	PageLetter = PageSize{8.5 * Inch, 11 * Inch}
+	PageLegal  = PageSize{8.5 * Inch, 14 * Inch}
+)
+
+// DefaultPosterMargin is the margin left around each page if none is
+// given, which most printers can't print into anyway
+const DefaultPosterMargin = 10
+
+// PosterOptions controls how a phrase is printed. All lengths are in
+// millimetres, so multiply by Inch to give them in inches.
+type PosterOptions struct {
+	// Width and Height are the size of the poster, including the
+	// padding. If only one of them is given, the other keeps the
+	// aspect ratio of the phrase, and if neither is given the phrase
+	// is printed at 72 pixels per inch.
+	Width, Height float64
+	// Padding is the background left around the phrase
+	Padding float64
+	// Page is the size of paper the poster is split across, which is
+	// A4 if it isn't given
+	Page PageSize
+	// Landscape turns the pages on their side
+	Landscape bool
+	// Margin is left unprinted around the edge of each page, and
+	// holds the crop marks. It is DefaultPosterMargin if zero, and
+	// there are no marks if it is negative.
+	Margin float64
+	// Overlap is how much of the poster each page repeats from the
+	// page before it, to glue the next page onto
+	Overlap float64
+	// Duration is how long the keyframes are played for before the
+	// phrase is printed. If it is zero, they are played until they
+	// finish.
+	Duration time.Duration
+}
+
+// cropMarkColor is the grey the crop marks and labels are printed in
+var cropMarkColor = [3]int{0x60, 0x60, 0x60}
+
+// WritePoster prints how the keyframes finish as a PDF poster. Posters
+// larger than a page are split across as many pages as they need,
+// working across and then down, with crop marks at the edges of the
+// printed area. Pages after the first in each row and column start
+// Overlap before the page they follow ends, and the page they follow
+// marks where they should line up when they are trimmed and glued on.
+func WritePoster(w io.Writer, keyframes []timeline.Keyframe, opts PosterOptions) error {
+	if opts.Page.Width <= 0 || opts.Page.Height <= 0 {
+		opts.Page = PageA4
+	}
+	if opts.Margin == 0 {
+		opts.Margin = DefaultPosterMargin
+	}
+	margin := math.Max(opts.Margin, 0)
+	pageWidth, pageHeight := opts.Page.Width, opts.Page.Height
+	orientation := "P"
+	if opts.Landscape {
+		pageWidth, pageHeight = pageHeight, pageWidth
+		orientation = "L"
+	}
+	// the area of the poster each page prints
+	areaWidth, areaHeight := pageWidth-2*margin, pageHeight-2*margin
+	if areaWidth <= opts.Overlap || areaHeight <= opts.Overlap {
+		return errors.New("poster margins and overlap leave no room on the page")
+	}
+
+	scene, err := still(keyframes, Options{Duration: opts.Duration}.withDefaults())
+	if err != nil {
+		return err
+	}
+	topLeft, bottomRight, _ := scene.Bounds()
+	sceneWidth, sceneHeight := bottomRight[0]-topLeft[0], bottomRight[1]-topLeft[1]
+
+	// scale is the size of a pixel of the scene in millimetres
+	scale := Inch / draw2dpdf.DPI
+	width, height := sceneWidth*scale+2*opts.Padding, sceneHeight*scale+2*opts.Padding
+	var offset [2]float64
+	switch {
+	case opts.Width > 0 && opts.Height > 0:
+		scale = math.Min((opts.Width-2*opts.Padding)/sceneWidth, (opts.Height-2*opts.Padding)/sceneHeight)
+		offset = [2]float64{
+			(opts.Width - 2*opts.Padding - sceneWidth*scale) / 2,
+			(opts.Height - 2*opts.Padding - sceneHeight*scale) / 2,
+		}
+		width, height = opts.Width, opts.Height
+	case opts.Width > 0:
+		scale = (opts.Width - 2*opts.Padding) / sceneWidth
+		width, height = opts.Width, sceneHeight*scale+2*opts.Padding
+	case opts.Height > 0:
+		scale = (opts.Height - 2*opts.Padding) / sceneHeight
+		width, height = sceneWidth*scale+2*opts.Padding, opts.Height
+	}
+	if scale <= 0 {
+		return errors.New("poster padding leaves no room for the phrase")
+	}
+
+	// pages overlap, so each one after the first adds less than a
+	// whole page to the poster
+	stepX, stepY := areaWidth-opts.Overlap, areaHeight-opts.Overlap
+	columns := int(math.Max(1, math.Ceil((width-opts.Overlap)/stepX)))
+	rows := int(math.Max(1, math.Ceil((height-opts.Overlap)/stepY)))
+
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		OrientationStr: orientation,
+		UnitStr:        "mm",
+		Size:           gofpdf.SizeType{Wd: opts.Page.Width, Ht: opts.Page.Height},
+	})
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetFont("Helvetica", "", 7)
+	gc := draw2dpdf.NewGraphicContext(pdf)
+	for row := 0; row < rows; row++ {
+		for column := 0; column < columns; column++ {
+			pdf.AddPage()
+			// where the poster starts on this page
+			x, y := margin-float64(column)*stepX, margin-float64(row)*stepY
+			printedWidth := math.Min(areaWidth, width-float64(column)*stepX)
+			printedHeight := math.Min(areaHeight, height-float64(row)*stepY)
+
+			pdf.ClipRect(margin, margin, printedWidth, printedHeight, false)
+			gc.SetFillColor(scene.Background())
+			draw2dkit.Rectangle(gc, x, y, x+width, y+height)
+			gc.Fill()
+			gc.Save()
+			gc.Translate(x+opts.Padding+offset[0], y+opts.Padding+offset[1])
+			gc.Scale(scale, scale)
+			gc.Translate(-topLeft[0], -topLeft[1])
+			for _, cell := range scene.Visible() {
+				cell.Draw(gc)
+			}
+			gc.Restore()
+			pdf.ClipEnd()
+
+			if opts.Margin > 0 {
+				pdf.SetAlpha(1, "Normal")
+				writeCropMarks(pdf, margin, printedWidth, printedHeight,
+					column < columns-1, row < rows-1, opts.Overlap)
+				// restoring the graphic context resets the font size
+				pdf.SetFont("Helvetica", "", 7)
+				pdf.SetTextColor(cropMarkColor[0], cropMarkColor[1], cropMarkColor[2])
+				pdf.Text(margin, pageHeight-margin/3, fmt.Sprintf("row %d of %d, column %d of %d", row+1, rows, column+1, columns))
+			}
+		}
+	}
+	return pdf.Output(w)
+}
+
+// writeCropMarks prints marks in the margin at the corners of the
+// printed area of a page. If another page follows to the right or
+// below, the place it overlaps from is marked too.
+func writeCropMarks(pdf *gofpdf.Fpdf, margin, width, height float64, right, below bool, overlap float64) {
+	pdf.SetDrawColor(cropMarkColor[0], cropMarkColor[1], cropMarkColor[2])
+	pdf.SetLineWidth(0.2)
+	// the marks stop short of the printed area, so that they don't
+	// show if the page is trimmed slightly inside them
+	gap, length := margin/4, margin/2
+	left, top := margin, margin
+	xs := []float64{left, left + width}
+	ys := []float64{top, top + height}
+	if right && overlap > 0 {
+		xs = append(xs, left+width-overlap)
+	}
+	if below && overlap > 0 {
+		ys = append(ys, top+height-overlap)
+	}
+	for _, x := range xs {
+		pdf.Line(x, top-gap, x, top-gap-length)
+		pdf.Line(x, top+height+gap, x, top+height+gap+length)
+	}
+	for _, y := range ys {
+		pdf.Line(left-gap, y, left-gap-length, y)
+		pdf.Line(left+width+gap, y, left+width+gap+length, y)
+	}
+}
@@ -0,0 +1,64 @@
+package render
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+)
+
+var (
+	pdfPage     = regexp.MustCompile(`/Type /Page\b[^s]`)
+	pdfMediaBox = regexp.MustCompile(`/MediaBox \[0 0 ([0-9.]+) ([0-9.]+)\]`)
+)
+
+func TestWritePosterTiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  PosterOptions
+		pages int
+		// size is the size of each page in points
+		size [2]string
+	}{
+		{"one page", PosterOptions{Width: 150}, 1, [2]string{"595.28", "841.89"}},
+		// A4 prints 190mm across inside the margins
+		{"portrait", PosterOptions{Width: 500}, 3, [2]string{"595.28", "841.89"}},
+		{"landscape", PosterOptions{Width: 500, Landscape: true}, 2, [2]string{"841.89", "595.28"}},
+		{"overlap", PosterOptions{Width: 500, Overlap: 40}, 4, [2]string{"595.28", "841.89"}},
+		{"rows", PosterOptions{Width: 500, Height: 300}, 6, [2]string{"595.28", "841.89"}},
+		{"no margin", PosterOptions{Width: 420, Page: PageA3, Margin: -1}, 2, [2]string{"841.89", "1190.55"}},
+	}
+	keyframes := parseKeyframes(t, `[{"at": 0, "action": "phrase", "text": "SNAKE IS DEAD"}]`)
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := WritePoster(&buf, keyframes, test.opts); err != nil {
+			t.Errorf("%s: WritePoster returned error: %s", test.name, err)
+			continue
+		}
+		if pages := len(pdfPage.FindAll(buf.Bytes(), -1)); pages != test.pages {
+			t.Errorf("%s: poster has %d pages, want %d", test.name, pages, test.pages)
+		}
+		boxes := pdfMediaBox.FindAllSubmatch(buf.Bytes(), -1)
+		if len(boxes) == 0 {
+			t.Errorf("%s: poster has no page size", test.name)
+		}
+		for _, box := range boxes {
+			if size := [2]string{string(box[1]), string(box[2])}; size != test.size {
+				t.Errorf("%s: poster has pages of %s, want %s", test.name, size, test.size)
+			}
+		}
+	}
+}
+
+func TestWritePosterNoRoom(t *testing.T) {
+	keyframes := parseKeyframes(t, `[{"at": 0, "action": "phrase", "text": "SNAKE"}]`)
+	tests := []PosterOptions{
+		{Margin: 110},
+		{Overlap: 200},
+		{Width: 100, Padding: 50},
+	}
+	for _, opts := range tests {
+		if err := WritePoster(&bytes.Buffer{}, keyframes, opts); err == nil {
+			t.Errorf("WritePoster with %+v returned no error", opts)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/joshbarrass/SnakeIsDead/pkg/timeline"
+)
+
+// FrameFilePattern is the format of the names of the files written by
+// WritePNGFiles, given the number of the frame
+const FrameFilePattern = "frame-%05d.png"
+
+// WritePNG renders how the keyframes finish as a single PNG, which is
+// the last frame of the animation
+func WritePNG(w io.Writer, keyframes []timeline.Keyframe, opts Options) error {
+	var last *image.RGBA
+	err := Frames(keyframes, opts, func(frame *image.RGBA, changed bool) error {
+		last = frame
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, last)
+}
+
+// WritePNGs renders the keyframes as a PNG for every frame. create is
+// called with the number of each frame, counting from zero, to get
+// where to write it.
+func WritePNGs(keyframes []timeline.Keyframe, opts Options, create func(index int) (io.WriteCloser, error)) error {
+	encoder := &png.Encoder{}
+	index := 0
+	return Frames(keyframes, opts, func(frame *image.RGBA, changed bool) error {
+		w, err := create(index)
+		if err != nil {
+			return err
+		}
+		index++
+		if err := encoder.Encode(w, frame); err != nil {
+			w.Close()
+			return err
+		}
+		return w.Close()
+	})
+}
+
+// WritePNGFiles renders the keyframes as numbered PNG files in a
+// directory, named using FrameFilePattern
+func WritePNGFiles(dir string, keyframes []timeline.Keyframe, opts Options) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return WritePNGs(keyframes, opts, func(index int) (io.WriteCloser, error) {
+		return os.Create(filepath.Join(dir, fmt.Sprintf(FrameFilePattern, index)))
+	})
+}
@@ -0,0 +1,171 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// trackedWriter records whether it has been closed, and fails writes
+// if it is given an error
+type trackedWriter struct {
+	bytes.Buffer
+	err    error
+	closed bool
+}
+
+// Write writes to the buffer, unless the writer has an error
+func (w *trackedWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	return w.Buffer.Write(p)
+}
+
+// Close marks the writer as closed
+func (w *trackedWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestWritePNGFiles(t *testing.T) {
+	keyframes := parseKeyframes(t, `[{"at": 0, "action": "phrase", "text": "AB"}]`)
+	opts := Options{FPS: 10, Duration: 500 * time.Millisecond, Width: 120}
+	// the directory is made if it doesn't exist
+	dir := filepath.Join(t.TempDir(), "frames")
+	if err := WritePNGFiles(dir, keyframes, opts); err != nil {
+		t.Fatalf("WritePNGFiles returned error: %s", err)
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	want := []string{"frame-00000.png", "frame-00001.png", "frame-00002.png", "frame-00003.png", "frame-00004.png"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("WritePNGFiles wrote %v, want %v", names, want)
+	}
+
+	// every frame is the size of the one WritePNG writes, and the
+	// last is the same as it
+	var buf bytes.Buffer
+	if err := WritePNG(&buf, keyframes, opts); err != nil {
+		t.Fatal(err)
+	}
+	final, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last image.Image
+	for _, name := range names {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("could not decode %s: %s", name, err)
+		}
+		if got := img.Bounds().Dx(); got != 120 {
+			t.Errorf("%s is %d wide, want 120", name, got)
+		}
+		if img.Bounds() != final.Bounds() {
+			t.Errorf("%s is %v, want %v", name, img.Bounds(), final.Bounds())
+		}
+		last = img
+	}
+
+	if !reflect.DeepEqual(last, final) {
+		t.Errorf("%s isn't the same as the image WritePNG writes", names[len(names)-1])
+	}
+}
+
+func TestWritePNGsErrors(t *testing.T) {
+	keyframes := parseKeyframes(t, `[{"at": 0, "action": "phrase", "text": "AB"}]`)
+	opts := Options{FPS: 10, Duration: 500 * time.Millisecond}
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name string
+		// create returns the writer for a frame, or an error
+		create func(index int) (*trackedWriter, error)
+		// frames is how many frames create is called for
+		frames int
+	}{
+		{"create", func(index int) (*trackedWriter, error) {
+			if index == 2 {
+				return nil, errFailed
+			}
+			return &trackedWriter{}, nil
+		}, 3},
+		{"write", func(index int) (*trackedWriter, error) {
+			if index == 1 {
+				return &trackedWriter{err: errFailed}, nil
+			}
+			return &trackedWriter{}, nil
+		}, 2},
+	}
+	for _, test := range tests {
+		indices := []int{}
+		writers := []*trackedWriter{}
+		err := WritePNGs(keyframes, opts, func(index int) (io.WriteCloser, error) {
+			indices = append(indices, index)
+			w, err := test.create(index)
+			if err != nil {
+				return nil, err
+			}
+			writers = append(writers, w)
+			return w, nil
+		})
+		if !errors.Is(err, errFailed) {
+			t.Errorf("%s: WritePNGs returned error %v, want %v", test.name, err, errFailed)
+		}
+		wantIndices := []int{}
+		for i := 0; i < test.frames; i++ {
+			wantIndices = append(wantIndices, i)
+		}
+		if !reflect.DeepEqual(indices, wantIndices) {
+			t.Errorf("%s: create was called for frames %v, want %v", test.name, indices, wantIndices)
+		}
+		for i, w := range writers {
+			if !w.closed {
+				t.Errorf("%s: frame %d was left open", test.name, i)
+			}
+		}
+	}
+}
+
+func TestWritePNGFilesCreateError(t *testing.T) {
+	// a file in the way of a frame stops the frames being written
+	keyframes := parseKeyframes(t, `[{"at": 0, "action": "phrase", "text": "AB"}]`)
+	opts := Options{FPS: 10, Duration: 500 * time.Millisecond}
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, fmt.Sprintf(FrameFilePattern, 2)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := WritePNGFiles(dir, keyframes, opts); err == nil {
+		t.Error("WritePNGFiles returned no error")
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := os.Stat(filepath.Join(dir, fmt.Sprintf(FrameFilePattern, i))); err != nil {
+			t.Errorf("frame %d before the failure wasn't written: %s", i, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, fmt.Sprintf(FrameFilePattern, 3))); !os.IsNotExist(err) {
+		t.Error("frames were written after the failure")
+	}
+}
@@ -0,0 +1,226 @@
+package render
+
+import (
+	"errors"
+	"image"
+	"math"
+	"time"
+
+	"github.com/joshbarrass/SnakeIsDead/pkg/letters"
+	"github.com/joshbarrass/SnakeIsDead/pkg/timeline"
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dimg"
+)
+
+// DefaultFPS is the frame rate used if none is given
+const DefaultFPS = 30
+
+// MaxDuration is the longest an animation is rendered for when it
+// isn't given a duration
+const MaxDuration = time.Minute
+
+// ErrEmpty is returned when an animation never shows anything, so
+// there is nothing to size the frames to
+var ErrEmpty = errors.New("animation has no cells")
+
+// Options controls how an animation is rendered
+type Options struct {
+	// Width and Height are the size of each frame in pixels. The
+	// frames are sized to fit everything the animation shows, plus
+	// the padding, and then scaled to the given size. If only one of
+	// them is given, the other keeps the animation's aspect ratio,
+	// and if neither is given the animation is not scaled.
+	Width, Height int
+	// Padding is the space left around the animation, before scaling
+	Padding float64
+	// FPS is the number of frames per second
+	FPS float64
+	// Duration is how long to render for. If it is zero, rendering
+	// stops once every keyframe has finished and nothing is
+	// animating, or after MaxDuration.
+	Duration time.Duration
+	// TopLeft is where the phrase starts in the scene. This only
+	// matters to keyframes that depend on the position of the
+	// cells.
+	TopLeft [2]float64
+}
+
+// frameCount returns the number of frames to render, or -1 if the
+// animation should run until it finishes
+func (opts *Options) frameCount() int {
+	if opts.Duration <= 0 {
+		return -1
+	}
+	return int(opts.Duration.Seconds()*opts.FPS + 0.5)
+}
+
+// withDefaults returns the options with any unset values filled in
+func (opts Options) withDefaults() Options {
+	if opts.FPS <= 0 {
+		opts.FPS = DefaultFPS
+	}
+	return opts
+}
+
+// newTimeline creates a timeline playing the keyframes on a new
+// scene, with a clock that steps a frame at a time
+func newTimeline(keyframes []timeline.Keyframe, opts Options) (*timeline.Timeline, *letters.FixedStepClock) {
+	clock := letters.NewFrameClock(opts.FPS)
+	scene := timeline.NewScene(opts.TopLeft)
+	scene.Clock = clock
+	tl := timeline.New(scene)
+	tl.Add(keyframes...)
+	return tl, clock
+}
+
+// step plays the timeline for the number of frames given by the
+// options, calling fn with whether each frame changed
+func step(tl *timeline.Timeline, clock *letters.FixedStepClock, gc draw2d.GraphicContext, opts Options, fn func(changed bool) error) error {
+	count := opts.frameCount()
+	limit := int(MaxDuration.Seconds() * opts.FPS)
+	for i := 0; count < 0 && i < limit || i < count; i++ {
+		changed := tl.Draw(gc)
+		if err := fn(changed); err != nil {
+			return err
+		}
+		// without a duration, stop once the animation has finished
+		if count < 0 && !changed && tl.Done() {
+			break
+		}
+		clock.Tick()
+	}
+	return nil
+}
+
+// framing holds the size of the frames and where the scene is drawn
+// in them
+type framing struct {
+	width, height int
+	scale         float64
+	// origin is the point of the scene drawn at the top left of the
+	// frame
+	origin [2]float64
+	// offset centres the animation when it doesn't fill the frame
+	offset [2]float64
+}
+
+// frame works out the size of the frames and where to draw the scene
+// in them, by playing the animation once to find everything it
+// covers
+func frame(keyframes []timeline.Keyframe, opts Options) (framing, error) {
+	tl, clock := newTimeline(keyframes, opts)
+	gc := draw2dimg.NewGraphicContext(image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	var topLeft, bottomRight [2]float64
+	found := false
+	err := step(tl, clock, gc, opts, func(changed bool) error {
+		frameTopLeft, frameBottomRight, ok := tl.Scene.Bounds()
+		if !ok {
+			return nil
+		}
+		if !found {
+			topLeft, bottomRight, found = frameTopLeft, frameBottomRight, true
+			return nil
+		}
+		topLeft[0] = math.Min(topLeft[0], frameTopLeft[0])
+		topLeft[1] = math.Min(topLeft[1], frameTopLeft[1])
+		bottomRight[0] = math.Max(bottomRight[0], frameBottomRight[0])
+		bottomRight[1] = math.Max(bottomRight[1], frameBottomRight[1])
+		return nil
+	})
+	if err != nil {
+		return framing{}, err
+	}
+	if !found {
+		return framing{}, ErrEmpty
+	}
+	return fit(topLeft, bottomRight, opts), nil
+}
+
+// fit works out the size of the frames and where to draw the scene in
+// them, for an animation covering the given area
+func fit(topLeft, bottomRight [2]float64, opts Options) framing {
+	width := bottomRight[0] - topLeft[0] + 2*opts.Padding
+	height := bottomRight[1] - topLeft[1] + 2*opts.Padding
+	f := framing{
+		scale:  1,
+		origin: [2]float64{topLeft[0] - opts.Padding, topLeft[1] - opts.Padding},
+	}
+	switch {
+	case opts.Width > 0 && opts.Height > 0:
+		f.width, f.height = opts.Width, opts.Height
+		f.scale = math.Min(float64(opts.Width)/width, float64(opts.Height)/height)
+		f.offset = [2]float64{
+			(float64(opts.Width) - width*f.scale) / 2,
+			(float64(opts.Height) - height*f.scale) / 2,
+		}
+	case opts.Width > 0:
+		f.scale = float64(opts.Width) / width
+		f.width, f.height = opts.Width, int(math.Ceil(height*f.scale))
+	case opts.Height > 0:
+		f.scale = float64(opts.Height) / height
+		f.width, f.height = int(math.Ceil(width*f.scale)), opts.Height
+	default:
+		f.width, f.height = int(math.Ceil(width)), int(math.Ceil(height))
+	}
+	return f
+}
+
+// newFrame creates an image the size of the frames, and a graphic
+// context that draws the scene onto it
+func newFrame(f framing) (*image.RGBA, *draw2dimg.GraphicContext) {
+	img := image.NewRGBA(image.Rect(0, 0, f.width, f.height))
+	gc := draw2dimg.NewGraphicContext(img)
+	gc.Translate(f.offset[0], f.offset[1])
+	gc.Scale(f.scale, f.scale)
+	gc.Translate(-f.origin[0], -f.origin[1])
+	return img, gc
+}
+
+// still plays the keyframes until they finish, or for the duration in
+// the options, and returns the scene as they leave it
+func still(keyframes []timeline.Keyframe, opts Options) (*timeline.Scene, error) {
+	tl, clock := newTimeline(keyframes, opts)
+	gc := draw2dimg.NewGraphicContext(image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	if err := step(tl, clock, gc, opts, func(changed bool) error { return nil }); err != nil {
+		return nil, err
+	}
+	if _, _, ok := tl.Scene.Bounds(); !ok {
+		return nil, ErrEmpty
+	}
+	return tl.Scene, nil
+}
+
+// FrameFunc is called with each frame as it is rendered, and whether
+// it differs from the frame before. The image is reused for the next
+// frame, so it must be copied if it is kept.
+type FrameFunc func(frame *image.RGBA, changed bool) error
+
+// Frames plays the keyframes on a new scene, stepping through them a
+// frame at a time, and calls fn with each frame. As the frames are
+// stepped rather than timed, the output is the same every time.
+func Frames(keyframes []timeline.Keyframe, opts Options, fn FrameFunc) error {
+	opts = opts.withDefaults()
+	f, err := frame(keyframes, opts)
+	if err != nil {
+		return err
+	}
+
+	tl, clock := newTimeline(keyframes, opts)
+	if f.scale < 1 {
+		// keep clearing a couple of pixels around each change
+		tl.Scene.Margin = 2 / f.scale
+	}
+	img, gc := newFrame(f)
+	return step(tl, clock, gc, opts, func(changed bool) error {
+		return fn(img, changed)
+	})
+}
+
+// PhraseKeyframes returns the keyframes for showing a single phrase
+func PhraseKeyframes(text string, font *letters.Font, policy letters.FallbackPolicy) ([]timeline.Keyframe, error) {
+	phrase, _, err := timeline.NewPhrase(text, font, policy)
+	if err != nil {
+		return nil, err
+	}
+	return []timeline.Keyframe{{Action: phrase}}, nil
+}
@@ -0,0 +1,119 @@
+package render
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/joshbarrass/SnakeIsDead/pkg/timeline"
+)
+
+// parseKeyframes parses a keyframe file for a test
+func parseKeyframes(t *testing.T, src string) []timeline.Keyframe {
+	t.Helper()
+	keyframes, err := timeline.ParseKeyframes(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyframes
+}
+
+// TestFramesClearStalePixels checks that drawing only the cells that
+// change leaves the last frame the same as drawing it from scratch.
+// The ring of the Å reaches higher than the other diacritics, and
+// used to be left behind when the cell moved and changed letter.
+func TestFramesClearStalePixels(t *testing.T) {
+	keyframes := parseKeyframes(t, `[
+		{"at": 0, "action": "phrase", "text": "KS Å"},
+		{"at": 1000, "action": "translate", "dx": 7, "dy": 13, "over": 300},
+		{"at": 1500, "action": "scale", "factor": 1.3, "over": 300},
+		{"at": 2000, "action": "phrase", "text": "X", "morph": 400}
+	]`)
+	// the padding keeps the top of the ring inside the frame
+	opts := Options{Padding: 20}.withDefaults()
+
+	var last *image.RGBA
+	err := Frames(keyframes, opts, func(frame *image.RGBA, changed bool) error {
+		last = image.NewRGBA(frame.Rect)
+		copy(last.Pix, frame.Pix)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// play the keyframes again, and draw the whole of the scene as
+	// it finishes onto a new image
+	f, err := frame(keyframes, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tl, clock := newTimeline(keyframes, opts)
+	_, gc := newFrame(f)
+	if err := step(tl, clock, gc, opts, func(changed bool) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	fresh, gc := newFrame(f)
+	tl.Scene.Invalidate()
+	tl.Scene.Draw(gc)
+
+	stale := 0
+	for i := 0; i < len(fresh.Pix); i += 4 {
+		for j := i; j < i+4; j++ {
+			if fresh.Pix[j] != last.Pix[j] {
+				stale++
+				break
+			}
+		}
+	}
+	if stale > 0 {
+		t.Errorf("last frame has %d pixels that differ from drawing it from scratch", stale)
+	}
+}
+
+// TestFramesDeterministic checks that rendering the same keyframes
+// twice gives the same frames, as the clock is stepped a frame at a
+// time rather than following the time they take to render
+func TestFramesDeterministic(t *testing.T) {
+	keyframes := parseKeyframes(t, `[
+		{"at": 0, "stagger": 70, "action": "phrase", "text": "SNAKE IS DEAD"},
+		{"at": 900, "stagger": 30, "action": "mode", "mode": "paradox", "fade": 300},
+		{"at": 1300, "action": "phrase", "text": "PARADOX", "morph": 400},
+		{"at": 1800, "action": "scale", "factor": 0.8, "over": 250}
+	]`)
+	opts := Options{Width: 320, Padding: 10}
+
+	play := func() [][sha256.Size]byte {
+		sums := [][sha256.Size]byte{}
+		err := Frames(keyframes, opts, func(frame *image.RGBA, changed bool) error {
+			sums = append(sums, sha256.Sum256(frame.Pix))
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sums
+	}
+	first, second := play(), play()
+	if len(first) != len(second) {
+		t.Fatalf("rendered %d frames and then %d frames", len(first), len(second))
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("frame %d differs between renders", i)
+		}
+	}
+
+	var a, b bytes.Buffer
+	if err := WriteGIF(&a, keyframes, opts); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteGIF(&b, keyframes, opts); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a.Bytes(), b.Bytes()) {
+		t.Error("GIFs of the same keyframes differ")
+	}
+}
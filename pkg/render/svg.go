@@ -0,0 +1,141 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/joshbarrass/SnakeIsDead/pkg/letters"
+	"github.com/joshbarrass/SnakeIsDead/pkg/timeline"
+	"github.com/llgcode/draw2d"
+)
+
+// WriteSVG renders how the keyframes finish as an SVG document. Each
+// cell is a group with the class "letter", and each segment is its own
+// path with the classes "segment" and "segment-<ID>", and its ID in the
+// data-segment-id attribute, so that they can be styled with CSS. The
+// animation is played until it finishes, or for opts.Duration if it is
+// given, and the document is sized to fit the cells at that point in
+// the same way as the frames of the other outputs.
+func WriteSVG(w io.Writer, keyframes []timeline.Keyframe, opts Options) error {
+	opts = opts.withDefaults()
+	scene, err := still(keyframes, opts)
+	if err != nil {
+		return err
+	}
+	topLeft, bottomRight, _ := scene.Bounds()
+	f := fit(topLeft, bottomRight, opts)
+
+	out := bufio.NewWriter(w)
+	// the view box is in the coordinates of the scene, so that the
+	// paths don't need transforming
+	viewX := f.origin[0] - f.offset[0]/f.scale
+	viewY := f.origin[1] - f.offset[1]/f.scale
+	viewWidth := float64(f.width) / f.scale
+	viewHeight := float64(f.height) / f.scale
+	fmt.Fprintf(out, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(out, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"%s %s %s %s\">\n",
+		f.width, f.height, svgNumber(viewX), svgNumber(viewY), svgNumber(viewWidth), svgNumber(viewHeight))
+	fmt.Fprintf(out, "  <rect class=\"background\" x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" fill=\"%s\"/>\n",
+		svgNumber(viewX), svgNumber(viewY), svgNumber(viewWidth), svgNumber(viewHeight), svgColor(scene.Background()))
+	for _, cell := range scene.Visible() {
+		fmt.Fprintf(out, "  <g class=\"letter\">\n")
+		for _, path := range cell.Paths() {
+			writeSVGPath(out, path)
+		}
+		fmt.Fprintf(out, "  </g>\n")
+	}
+	fmt.Fprintf(out, "</svg>\n")
+	return out.Flush()
+}
+
+// writeSVGPath writes a segment's path as an SVG path element
+func writeSVGPath(w io.Writer, path letters.SegmentPath) {
+	c := color.NRGBAModel.Convert(path.Color).(color.NRGBA)
+	if c.A == 0 {
+		// segments that have faded out completely are left out
+		return
+	}
+	fmt.Fprintf(w, "    <path class=\"segment segment-%d\" data-segment-id=\"%d\" d=\"%s\" fill=\"%s\"",
+		path.ID, path.ID, svgPathData(path.Path), svgColor(c))
+	if c.A != 0xff {
+		fmt.Fprintf(w, " fill-opacity=\"%s\"", svgNumber(float64(c.A)/0xff))
+	}
+	fillRule := "evenodd"
+	if path.FillRule == draw2d.FillRuleWinding {
+		fillRule = "nonzero"
+	}
+	fmt.Fprintf(w, " fill-rule=\"%s\"/>\n", fillRule)
+}
+
+// svgPathData converts a draw2d path to the commands of an SVG path
+func svgPathData(path *draw2d.Path) string {
+	commands := []string{}
+	add := func(command string, values ...float64) {
+		parts := []string{command}
+		for _, v := range values {
+			parts = append(parts, svgNumber(v))
+		}
+		commands = append(commands, strings.Join(parts, " "))
+	}
+	j := 0
+	for _, cmp := range path.Components {
+		switch cmp {
+		case draw2d.MoveToCmp:
+			add("M", path.Points[j:j+2]...)
+			j += 2
+		case draw2d.LineToCmp:
+			add("L", path.Points[j:j+2]...)
+			j += 2
+		case draw2d.QuadCurveToCmp:
+			add("Q", path.Points[j:j+4]...)
+			j += 4
+		case draw2d.CubicCurveToCmp:
+			add("C", path.Points[j:j+6]...)
+			j += 6
+		case draw2d.ArcToCmp:
+			cx, cy, rx, ry := path.Points[j], path.Points[j+1], path.Points[j+2], path.Points[j+3]
+			start, angle := path.Points[j+4], path.Points[j+5]
+			j += 6
+			// draw2d has already moved to the start of the arc. SVG
+			// can't draw a whole ellipse as one arc, so it is drawn
+			// in halves.
+			steps := 1
+			if math.Abs(angle) > math.Pi {
+				steps = 2
+			}
+			sweep := 0.0
+			if angle > 0 {
+				sweep = 1
+			}
+			for i := 1; i <= steps; i++ {
+				end := start + angle*float64(i)/float64(steps)
+				add("A", rx, ry, 0, 0, sweep, cx+math.Cos(end)*rx, cy+math.Sin(end)*ry)
+			}
+		case draw2d.CloseCmp:
+			commands = append(commands, "Z")
+		}
+	}
+	return strings.Join(commands, " ")
+}
+
+// svgColor returns a colour as an SVG hex colour, ignoring its alpha
+func svgColor(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("#%02x%02x%02x", n.R, n.G, n.B)
+}
+
+// svgNumber formats a number to a precision that is plenty for
+// drawing, without any trailing zeros
+func svgNumber(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 3, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,133 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/joshbarrass/SnakeIsDead/pkg/letters"
+	"github.com/joshbarrass/SnakeIsDead/pkg/timeline"
+)
+
+// svgSegment is a segment's path read back from an SVG document
+type svgSegment struct {
+	class   string
+	id      string
+	opacity string
+}
+
+// readSVG parses an SVG document, returning the segment paths in each
+// letter group
+func readSVG(t *testing.T, data []byte) [][]svgSegment {
+	t.Helper()
+	groups := [][]svgSegment{}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("could not parse SVG: %s", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		attrs := map[string]string{}
+		for _, attr := range start.Attr {
+			attrs[attr.Name.Local] = attr.Value
+		}
+		switch start.Name.Local {
+		case "g":
+			if attrs["class"] == "letter" {
+				groups = append(groups, []svgSegment{})
+			}
+		case "path":
+			if len(groups) == 0 {
+				t.Fatal("path outside of a letter group")
+			}
+			last := len(groups) - 1
+			groups[last] = append(groups[last], svgSegment{attrs["class"], attrs["data-segment-id"], attrs["fill-opacity"]})
+		}
+	}
+	return groups
+}
+
+// segmentIDs returns the IDs of the segments each character is drawn
+// with in the default font
+func segmentIDs(t *testing.T, text string) [][]letters.SegmentID {
+	t.Helper()
+	phrase, _, err := letters.DefaultFont.Letters(text, letters.FallbackError)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := [][]letters.SegmentID{}
+	for _, letter := range phrase {
+		letterIDs := []letters.SegmentID{}
+		for _, seg := range letter {
+			letterIDs = append(letterIDs, seg.ID())
+		}
+		ids = append(ids, letterIDs)
+	}
+	return ids
+}
+
+func TestWriteSVG(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		// faded is whether the segments are part way through fading
+		// in
+		faded bool
+	}{
+		{"finished", 0, false},
+		{"mid-fade", letters.DefaultLifecycle.FadeIn / 2, true},
+	}
+	keyframes := parseKeyframes(t, `[{"at": 0, "action": "phrase", "text": "AB"}]`)
+	want := segmentIDs(t, "AB")
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := WriteSVG(&buf, keyframes, Options{Duration: test.duration}); err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		groups := readSVG(t, buf.Bytes())
+		got := [][]letters.SegmentID{}
+		for _, group := range groups {
+			ids := []letters.SegmentID{}
+			for _, seg := range group {
+				id, err := strconv.Atoi(seg.id)
+				if err != nil {
+					t.Errorf("%s: path has data-segment-id %q", test.name, seg.id)
+					continue
+				}
+				if want := "segment segment-" + seg.id; seg.class != want {
+					t.Errorf("%s: segment %d has class %q, want %q", test.name, id, seg.class, want)
+				}
+				if !test.faded {
+					if seg.opacity != "" {
+						t.Errorf("%s: segment %d has fill-opacity %s", test.name, id, seg.opacity)
+					}
+				} else if opacity, err := strconv.ParseFloat(seg.opacity, 64); err != nil || opacity < 0.45 || opacity > 0.55 {
+					t.Errorf("%s: segment %d has fill-opacity %q, want about 0.5", test.name, id, seg.opacity)
+				}
+				ids = append(ids, letters.SegmentID(id))
+			}
+			got = append(got, ids)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: SVG has segments %v, want %v", test.name, got, want)
+		}
+	}
+}
+
+func TestWriteSVGEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSVG(&buf, []timeline.Keyframe{}, Options{}); err != ErrEmpty {
+		t.Errorf("WriteSVG of no keyframes returned error %v, want %v", err, ErrEmpty)
+	}
+}
@@ -0,0 +1,67 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+
+	"github.com/joshbarrass/SnakeIsDead/pkg/timeline"
+)
+
+// frameRate returns the frame rate as a fraction, which is how YUV4MPEG2
+// gives it
+func frameRate(fps float64) (num, den int) {
+	num, den = int(math.Round(fps*1000)), 1000
+	a, b := num, den
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return num / a, den / a
+}
+
+// WriteY4M renders the keyframes as an uncompressed YUV4MPEG2 stream,
+// which video encoders such as ffmpeg can read from a pipe. Every
+// frame is written, so that the stream plays at a constant frame
+// rate. The colours are full range BT.601, without chroma
+// subsampling.
+func WriteY4M(w io.Writer, keyframes []timeline.Keyframe, opts Options) error {
+	opts = opts.withDefaults()
+	out := bufio.NewWriter(w)
+	var planes []byte
+	err := Frames(keyframes, opts, func(frame *image.RGBA, changed bool) error {
+		bounds := frame.Bounds()
+		if planes == nil {
+			num, den := frameRate(opts.FPS)
+			_, err := fmt.Fprintf(out, "YUV4MPEG2 W%d H%d F%d:%d Ip A1:1 C444 XCOLORRANGE=FULL\n", bounds.Dx(), bounds.Dy(), num, den)
+			if err != nil {
+				return err
+			}
+			planes = make([]byte, 3*bounds.Dx()*bounds.Dy())
+			changed = true
+		}
+		// an unchanged frame is the same as the one already converted
+		if changed {
+			size := bounds.Dx() * bounds.Dy()
+			i := 0
+			for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+				for x := bounds.Min.X; x < bounds.Max.X; x++ {
+					c := frame.RGBAAt(x, y)
+					planes[i], planes[size+i], planes[2*size+i] = color.RGBToYCbCr(c.R, c.G, c.B)
+					i++
+				}
+			}
+		}
+		if _, err := io.WriteString(out, "FRAME\n"); err != nil {
+			return err
+		}
+		_, err := out.Write(planes)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return out.Flush()
+}
@@ -0,0 +1,116 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFrameRate(t *testing.T) {
+	tests := []struct {
+		fps      float64
+		num, den int
+	}{
+		{30, 30, 1},
+		{25, 25, 1},
+		{29.97, 2997, 100},
+		{12.5, 25, 2},
+	}
+	for _, test := range tests {
+		if num, den := frameRate(test.fps); num != test.num || den != test.den {
+			t.Errorf("frameRate(%g) = %d:%d, want %d:%d", test.fps, num, den, test.num, test.den)
+		}
+	}
+}
+
+func TestWriteY4M(t *testing.T) {
+	keyframes := parseKeyframes(t, `[
+		{"at": 0, "action": "phrase", "text": "SNAKE"},
+		{"at": 200, "action": "translate", "dx": 10, "dy": 0, "over": 200}
+	]`)
+	opts := Options{Width: 64, FPS: 25, Duration: 480 * time.Millisecond}
+	var buf bytes.Buffer
+	if err := WriteY4M(&buf, keyframes, opts); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	end := bytes.IndexByte(data, '\n')
+	if end < 0 {
+		t.Fatal("output has no header line")
+	}
+	header := strings.Fields(string(data[:end]))
+	data = data[end+1:]
+	if len(header) == 0 || header[0] != "YUV4MPEG2" {
+		t.Fatalf("header %q doesn't start with YUV4MPEG2", header)
+	}
+	var width, height int
+	params := map[byte]string{}
+	colorRange := ""
+	for _, field := range header[1:] {
+		if strings.HasPrefix(field, "X") {
+			colorRange = field
+			continue
+		}
+		params[field[0]] = field[1:]
+	}
+	if _, err := fmt.Sscan(params['W'], &width); err != nil {
+		t.Fatalf("header has bad width %q", params['W'])
+	}
+	if _, err := fmt.Sscan(params['H'], &height); err != nil {
+		t.Fatalf("header has bad height %q", params['H'])
+	}
+	if width != 64 || height <= 0 {
+		t.Errorf("header gives size %dx%d, want width 64", width, height)
+	}
+	if params['F'] != "25:1" {
+		t.Errorf("header gives frame rate %q, want 25:1", params['F'])
+	}
+	if params['C'] != "444" {
+		t.Errorf("header gives colour space %q, want 444", params['C'])
+	}
+	if colorRange != "XCOLORRANGE=FULL" {
+		t.Errorf("header gives colour range %q, want XCOLORRANGE=FULL", colorRange)
+	}
+
+	// the frames follow, each a FRAME line and its three planes
+	size := 3 * width * height
+	frames := [][]byte{}
+	for len(data) > 0 {
+		if !bytes.HasPrefix(data, []byte("FRAME\n")) {
+			t.Fatalf("frame %d doesn't start with FRAME", len(frames)+1)
+		}
+		data = data[len("FRAME\n"):]
+		if len(data) < size {
+			t.Fatalf("frame %d is %d bytes, want %d", len(frames)+1, len(data), size)
+		}
+		frames = append(frames, data[:size])
+		data = data[size:]
+	}
+	if want := 12; len(frames) != want {
+		t.Fatalf("stream has %d frames, want %d", len(frames), want)
+	}
+
+	// the last frame holds the last image converted to YCbCr
+	var last *image.RGBA
+	err := Frames(keyframes, opts.withDefaults(), func(frame *image.RGBA, changed bool) error {
+		last = frame
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	planes := frames[len(frames)-1]
+	area := width * height
+	for i := 0; i < area; i++ {
+		c := last.RGBAAt(last.Rect.Min.X+i%width, last.Rect.Min.Y+i/width)
+		y, cb, cr := color.RGBToYCbCr(c.R, c.G, c.B)
+		if got := [3]byte{planes[i], planes[area+i], planes[2*area+i]}; got != [3]byte{y, cb, cr} {
+			t.Fatalf("pixel %d of the last frame is %v, want %v", i, got, [3]byte{y, cb, cr})
+		}
+	}
+}
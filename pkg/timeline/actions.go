@@ -0,0 +1,285 @@
+package timeline
+
+import (
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/joshbarrass/SnakeIsDead/pkg/letters"
+)
+
+// Phrase is an action that changes the phrase shown by the scene.
+// Each cell keeps the segments it shares with its new letter, and
+// fades the rest. If Morph is set, cells instead morph their outline
+// into the new letter over MorphDuration.
+type Phrase struct {
+	Text          string
+	Font          *letters.Font
+	Policy        letters.FallbackPolicy
+	Morph         bool
+	MorphDuration time.Duration
+
+	letters   []letters.Letter
+	positions [][2]float64
+	started   []bool
+}
+
+// NewPhrase creates a Phrase action, and returns any characters that
+// will be substituted when it is shown. A nil font means
+// letters.DefaultFont.
+func NewPhrase(text string, font *letters.Font, policy letters.FallbackPolicy) (*Phrase, []letters.Substitution, error) {
+	if font == nil {
+		font = letters.DefaultFont
+	}
+	_, substitutions, err := font.Letters(text, policy)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &Phrase{
+		Text:   text,
+		Font:   font,
+		Policy: policy,
+	}, substitutions, nil
+}
+
+// Start lays out the new phrase
+func (phrase *Phrase) Start(scene *Scene) int {
+	font := phrase.Font
+	if font == nil {
+		font = letters.DefaultFont
+	}
+	// the font may have changed since the action was created
+	newLetters, _, err := font.Letters(phrase.Text, phrase.Policy)
+	if err != nil {
+		newLetters, _, _ = font.Letters(phrase.Text, letters.FallbackTofu)
+	}
+	phrase.letters = newLetters
+	phrase.positions = scene.Layout(newLetters, font)
+
+	count := len(newLetters)
+	if len(scene.Cells) > count {
+		count = len(scene.Cells)
+	}
+	for len(scene.Cells) < count {
+		scene.Cells = append(scene.Cells, nil)
+	}
+	phrase.started = make([]bool, count)
+	return count
+}
+
+// Apply changes the cell at the index to its letter in the new
+// phrase
+func (phrase *Phrase) Apply(scene *Scene, index int, progress float64) {
+	if index >= len(scene.Cells) {
+		return
+	}
+	if !phrase.started[index] {
+		phrase.started[index] = true
+		phrase.begin(scene, index)
+	}
+	if cell := scene.Cells[index]; cell != nil && cell.Morphing() {
+		cell.MorphProgress = progress
+		if progress >= 1 {
+			cell.EndMorph()
+		}
+	}
+	// drop the cells past the end of the new phrase once every cell
+	// has changed
+	for _, started := range phrase.started {
+		if !started {
+			return
+		}
+	}
+	for len(scene.Cells) > 0 && scene.Cells[len(scene.Cells)-1] == nil {
+		scene.Cells = scene.Cells[:len(scene.Cells)-1]
+	}
+}
+
+// begin starts changing the cell at the index
+func (phrase *Phrase) begin(scene *Scene, index int) {
+	font := phrase.Font
+	if font == nil {
+		font = letters.DefaultFont
+	}
+	if index >= len(phrase.letters) {
+		scene.remove(index)
+		return
+	}
+	cell := scene.Cells[index]
+	if cell == nil || cell.Font != font {
+		scene.remove(index)
+		scene.Cells[index] = scene.newCell(phrase.letters[index], phrase.positions[index], font)
+		return
+	}
+	cell.Translate(phrase.positions[index][0]-cell.TopLeft[0], phrase.positions[index][1]-cell.TopLeft[1])
+	if phrase.Morph {
+		cell.MorphTo(phrase.letters[index])
+	} else {
+		cell.SetLetter(phrase.letters[index])
+	}
+}
+
+// Duration returns how long each cell takes to morph, if the phrase
+// is morphed in
+func (phrase *Phrase) Duration() time.Duration {
+	if !phrase.Morph {
+		return 0
+	}
+	return phrase.MorphDuration
+}
+
+// Mode is an action that changes the colour mode of the cells, fading
+// between the palettes over Fade
+type Mode struct {
+	Mode letters.ColorMode
+	Fade time.Duration
+}
+
+// Start makes the mode the one given to new cells
+func (mode *Mode) Start(scene *Scene) int {
+	scene.Mode = mode.Mode
+	return len(scene.Cells)
+}
+
+// Apply changes the mode of the cell at the index
+func (mode *Mode) Apply(scene *Scene, index int, progress float64) {
+	if index < len(scene.Cells) && scene.Cells[index] != nil {
+		scene.Cells[index].SetMode(mode.Mode, mode.Fade)
+	}
+}
+
+// Duration returns zero, as the cells fade between palettes by
+// themselves
+func (mode *Mode) Duration() time.Duration {
+	return 0
+}
+
+// Colors is an action that changes the palettes the cells are drawn
+// with in each colour mode
+type Colors struct {
+	Death   [2]color.RGBA
+	Paradox [2]color.RGBA
+}
+
+// Start makes the palettes the ones given to new cells
+func (c *Colors) Start(scene *Scene) int {
+	scene.DeathColors = c.Death
+	scene.ParadoxColors = c.Paradox
+	return len(scene.Cells)
+}
+
+// Apply changes the palettes of the cell at the index
+func (c *Colors) Apply(scene *Scene, index int, progress float64) {
+	if index < len(scene.Cells) && scene.Cells[index] != nil {
+		scene.Cells[index].SetPalettes(c.Death, c.Paradox)
+	}
+}
+
+// Duration returns zero, as the palettes change straight away
+func (c *Colors) Duration() time.Duration {
+	return 0
+}
+
+// Translate is an action that moves the cells by DX and DY over Over
+type Translate struct {
+	DX, DY float64
+	Over   time.Duration
+
+	progress []float64
+}
+
+// Start begins moving the cells
+func (t *Translate) Start(scene *Scene) int {
+	t.progress = make([]float64, len(scene.Cells))
+	return len(scene.Cells)
+}
+
+// Apply moves the cell at the index as far as it should be by now
+func (t *Translate) Apply(scene *Scene, index int, progress float64) {
+	step := progress - t.progress[index]
+	t.progress[index] = progress
+	if index < len(scene.Cells) && scene.Cells[index] != nil {
+		scene.Cells[index].Translate(t.DX*step, t.DY*step)
+	}
+}
+
+// Duration returns how long each cell takes to move
+func (t *Translate) Duration() time.Duration {
+	return t.Over
+}
+
+// Scale is an action that scales the cells about their centres by
+// Factor over Over
+type Scale struct {
+	Factor float64
+	Over   time.Duration
+
+	progress []float64
+}
+
+// Start begins scaling the cells
+func (s *Scale) Start(scene *Scene) int {
+	s.progress = make([]float64, len(scene.Cells))
+	return len(scene.Cells)
+}
+
+// Apply scales the cell at the index as far as it should be by now
+func (s *Scale) Apply(scene *Scene, index int, progress float64) {
+	step := progress - s.progress[index]
+	s.progress[index] = progress
+	if index < len(scene.Cells) && scene.Cells[index] != nil {
+		scene.Cells[index].Scale(math.Pow(s.Factor, step))
+	}
+}
+
+// Duration returns how long each cell takes to scale
+func (s *Scale) Duration() time.Duration {
+	return s.Over
+}
+
+// FadeSegments is an action that fades out the segments with any of
+// the given IDs
+type FadeSegments struct {
+	IDs []letters.SegmentID
+}
+
+// Start returns the number of cells
+func (f *FadeSegments) Start(scene *Scene) int {
+	return len(scene.Cells)
+}
+
+// Apply fades out the segments of the cell at the index
+func (f *FadeSegments) Apply(scene *Scene, index int, progress float64) {
+	if index < len(scene.Cells) && scene.Cells[index] != nil {
+		scene.Cells[index].FadeOutSegments(f.IDs...)
+	}
+}
+
+// Duration returns zero, as the segments fade by themselves
+func (f *FadeSegments) Duration() time.Duration {
+	return 0
+}
+
+// CellFunc is an action that calls a function on each cell, for
+// animations not covered by the other actions
+type CellFunc struct {
+	Func func(cell *letters.Cell, index int, progress float64)
+	Over time.Duration
+}
+
+// Start returns the number of cells
+func (f *CellFunc) Start(scene *Scene) int {
+	return len(scene.Cells)
+}
+
+// Apply calls the function on the cell at the index
+func (f *CellFunc) Apply(scene *Scene, index int, progress float64) {
+	if index < len(scene.Cells) && scene.Cells[index] != nil {
+		f.Func(scene.Cells[index], index, progress)
+	}
+}
+
+// Duration returns how long the function is called for on each cell
+func (f *CellFunc) Duration() time.Duration {
+	return f.Over
+}
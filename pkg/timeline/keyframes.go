@@ -0,0 +1,128 @@
+package timeline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/joshbarrass/SnakeIsDead/pkg/letters"
+)
+
+// A keyframe file is a JSON array of keyframes. Times are given in
+// milliseconds, and each keyframe names its action along with the
+// action's options. For example:
+//
+//  [
+//    {"at": 0, "stagger": 100, "action": "phrase", "text": "SNAKE IS DEAD"},
+//    {"at": 2000, "action": "phrase", "text": "PARADOX", "font": "heavy", "policy": "tofu", "morph": 500},
+//    {"at": 3000, "stagger": 50, "action": "mode", "mode": "paradox", "fade": 1000},
+//    {"at": 4000, "action": "translate", "dx": 0, "dy": 20, "over": 500},
+//    {"at": 4000, "action": "scale", "factor": 1.5, "over": 500},
+//    {"at": 5000, "action": "fade-segments", "ids": [3, 4]}
+//  ]
+
+// keyframeFileEntry is the structure of a keyframe in a keyframe file
+type keyframeFileEntry struct {
+	At      float64 `json:"at"`
+	Stagger float64 `json:"stagger"`
+	Action  string  `json:"action"`
+
+	// phrase
+	Text   string  `json:"text"`
+	Font   string  `json:"font"`
+	Policy string  `json:"policy"`
+	Morph  float64 `json:"morph"`
+
+	// mode
+	Mode string  `json:"mode"`
+	Fade float64 `json:"fade"`
+
+	// translate and scale
+	DX     float64  `json:"dx"`
+	DY     float64  `json:"dy"`
+	Factor *float64 `json:"factor"`
+	Over   float64  `json:"over"`
+
+	// fade-segments
+	IDs []letters.SegmentID `json:"ids"`
+}
+
+// milliseconds converts a number of milliseconds to a Duration
+func milliseconds(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// action creates the action described by the entry
+func (entry *keyframeFileEntry) action() (Action, error) {
+	switch entry.Action {
+	case "phrase":
+		font := letters.DefaultFont
+		if entry.Font != "" {
+			var ok bool
+			font, ok = letters.LookupFont(entry.Font)
+			if !ok {
+				return nil, fmt.Errorf("font '%s': %w", entry.Font, letters.ErrFontNotRegistered)
+			}
+		}
+		policy := letters.FallbackError
+		if entry.Policy != "" {
+			var err error
+			policy, err = letters.ParseFallbackPolicy(entry.Policy)
+			if err != nil {
+				return nil, err
+			}
+		}
+		phrase, _, err := NewPhrase(entry.Text, font, policy)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Morph > 0 {
+			phrase.Morph = true
+			phrase.MorphDuration = milliseconds(entry.Morph)
+		}
+		return phrase, nil
+	case "mode":
+		mode, err := letters.ParseColorMode(entry.Mode)
+		if err != nil {
+			return nil, err
+		}
+		return &Mode{Mode: mode, Fade: milliseconds(entry.Fade)}, nil
+	case "translate":
+		return &Translate{DX: entry.DX, DY: entry.DY, Over: milliseconds(entry.Over)}, nil
+	case "scale":
+		if entry.Factor == nil || *entry.Factor <= 0 {
+			return nil, errors.New("scale needs a positive factor")
+		}
+		return &Scale{Factor: *entry.Factor, Over: milliseconds(entry.Over)}, nil
+	case "fade-segments":
+		return &FadeSegments{IDs: entry.IDs}, nil
+	default:
+		return nil, fmt.Errorf("unknown action '%s'", entry.Action)
+	}
+}
+
+// ParseKeyframes parses a keyframe file
+func ParseKeyframes(r io.Reader) ([]Keyframe, error) {
+	var entries []keyframeFileEntry
+	// misspelt options would otherwise be silently ignored
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&entries); err != nil {
+		return nil, fmt.Errorf("could not parse keyframe file: %w", err)
+	}
+	keyframes := make([]Keyframe, len(entries))
+	for i, entry := range entries {
+		action, err := entry.action()
+		if err != nil {
+			return nil, fmt.Errorf("keyframe %d: %w", i, err)
+		}
+		keyframes[i] = Keyframe{
+			At:      milliseconds(entry.At),
+			Stagger: milliseconds(entry.Stagger),
+			Action:  action,
+		}
+	}
+	return keyframes, nil
+}
@@ -0,0 +1,289 @@
+package timeline
+
+import (
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/joshbarrass/SnakeIsDead/pkg/letters"
+	"github.com/llgcode/draw2d"
+)
+
+// DefaultLetterSpacing is the space in pixels between the left edge
+// of letters
+const DefaultLetterSpacing = 100
+
+// Scene holds the cells of the phrase being shown
+type Scene struct {
+	Cells         []*letters.Cell
+	TopLeft       [2]float64
+	LetterSpacing float64
+	// Mode is the colour mode given to new cells
+	Mode letters.ColorMode
+	// DeathColors and ParadoxColors are the palettes given to new
+	// cells
+	DeathColors   [2]color.RGBA
+	ParadoxColors [2]color.RGBA
+	// Clock is the time source given to new cells, and used by any
+	// timeline playing the scene. It is the real time if nil.
+	Clock letters.Clock
+	// Margin is extra space cleared around cells that have changed,
+	// to cover the antialiasing at their edges when the scene is
+	// drawn scaled down
+	Margin float64
+
+	// leaving holds cells that are no longer part of the phrase, but
+	// are still fading out
+	leaving []*letters.Cell
+	// painted is whether the whole scene has been painted, after
+	// which only the parts that change are drawn again
+	painted    bool
+	background color.RGBA
+	// drawn holds the area each cell covered when it was last drawn
+	drawn map[*letters.Cell]area
+}
+
+// NewScene creates an empty Scene with its phrase starting at the
+// given position
+func NewScene(topLeft [2]float64) *Scene {
+	return &Scene{
+		TopLeft:       topLeft,
+		LetterSpacing: DefaultLetterSpacing,
+		DeathColors:   letters.ColorsDeath,
+		ParadoxColors: letters.ColorsParadox,
+	}
+}
+
+// now returns the current time according to the scene's clock
+func (scene *Scene) now() time.Time {
+	if scene.Clock == nil {
+		return time.Now()
+	}
+	return scene.Clock.Now()
+}
+
+// Layout returns the position of the top left of each letter of a
+// phrase. Letters that will be drawn in one of the scene's existing
+// cells are spaced using that cell's style.
+func (scene *Scene) Layout(phrase []letters.Letter, font *letters.Font) [][2]float64 {
+	positions := make([][2]float64, len(phrase))
+	x := scene.TopLeft[0]
+	for i, letter := range phrase {
+		positions[i] = [2]float64{x, scene.TopLeft[1]}
+		style := &font.Style
+		if i < len(scene.Cells) && scene.Cells[i] != nil && scene.Cells[i].Font == font {
+			style = &scene.Cells[i].Style
+		}
+		// narrower letters, such as punctuation, keep the same gap
+		// after them as a full width letter
+		x += scene.LetterSpacing - letters.DefaultWidth + font.AdvanceWidthIn(letter, style)
+	}
+	return positions
+}
+
+// newCell creates a cell for a letter at the given position
+func (scene *Scene) newCell(letter letters.Letter, topLeft [2]float64, font *letters.Font) *letters.Cell {
+	cell := letters.NewCell(
+		topLeft,
+		[2]float64{topLeft[0] + font.Width, topLeft[1] + font.Height},
+		letter,
+		scene.DeathColors,
+		scene.ParadoxColors,
+		font,
+	)
+	cell.Clock = scene.Clock
+	cell.SetMode(scene.Mode, 0)
+	return cell
+}
+
+// remove takes the cell at the given index out of the phrase and
+// fades it out
+func (scene *Scene) remove(index int) {
+	cell := scene.Cells[index]
+	if cell == nil {
+		return
+	}
+	cell.FadeOut()
+	scene.leaving = append(scene.leaving, cell)
+	scene.Cells[index] = nil
+}
+
+// area is a rectangle of the scene
+type area struct {
+	topLeft, bottomRight [2]float64
+}
+
+// intersects returns whether the areas overlap
+func (a area) intersects(b area) bool {
+	return a.topLeft[0] < b.bottomRight[0] && b.topLeft[0] < a.bottomRight[0] &&
+		a.topLeft[1] < b.bottomRight[1] && b.topLeft[1] < a.bottomRight[1]
+}
+
+// area returns the area between two corners, widened by the margin
+func (scene *Scene) area(topLeft, bottomRight [2]float64) area {
+	return area{
+		[2]float64{topLeft[0] - scene.Margin, topLeft[1] - scene.Margin},
+		[2]float64{bottomRight[0] + scene.Margin, bottomRight[1] + scene.Margin},
+	}
+}
+
+// fillArea fills an area of the context with a colour
+func fillArea(gc draw2d.GraphicContext, a area, c color.Color) {
+	gc.SetFillColor(c)
+	gc.MoveTo(a.topLeft[0], a.topLeft[1])
+	gc.LineTo(a.bottomRight[0], a.topLeft[1])
+	gc.LineTo(a.bottomRight[0], a.bottomRight[1])
+	gc.LineTo(a.topLeft[0], a.bottomRight[1])
+	gc.Close()
+	gc.Fill()
+}
+
+// Bounds returns the top left and bottom right of the area covered
+// by the scene's cells, and whether it has any
+func (scene *Scene) Bounds() (topLeft, bottomRight [2]float64, ok bool) {
+	cells := append(append([]*letters.Cell{}, scene.Cells...), scene.leaving...)
+	for _, cell := range cells {
+		if cell == nil {
+			continue
+		}
+		cellTopLeft, cellBottomRight := cell.Bounds()
+		if !ok {
+			topLeft, bottomRight, ok = cellTopLeft, cellBottomRight, true
+			continue
+		}
+		topLeft[0] = math.Min(topLeft[0], cellTopLeft[0])
+		topLeft[1] = math.Min(topLeft[1], cellTopLeft[1])
+		bottomRight[0] = math.Max(bottomRight[0], cellBottomRight[0])
+		bottomRight[1] = math.Max(bottomRight[1], cellBottomRight[1])
+	}
+	return
+}
+
+// Visible returns the cells that are drawn, which are those of the
+// phrase followed by any still fading out
+func (scene *Scene) Visible() []*letters.Cell {
+	cells := []*letters.Cell{}
+	for _, cell := range scene.Cells {
+		if cell != nil {
+			cells = append(cells, cell)
+		}
+	}
+	return append(cells, scene.leaving...)
+}
+
+// Background returns the colour behind the cells. This follows the
+// first cell, so that the whole screen changes when the phrase changes
+// mode.
+func (scene *Scene) Background() color.RGBA {
+	for _, cell := range scene.Cells {
+		if cell != nil {
+			return cell.Colors()[0]
+		}
+	}
+	if scene.Mode == letters.ModeParadox {
+		return scene.ParadoxColors[0]
+	}
+	return scene.DeathColors[0]
+}
+
+// Invalidate makes the next Draw repaint the whole scene, such as
+// when the image it is drawn onto has been cleared
+func (scene *Scene) Invalidate() {
+	scene.painted = false
+}
+
+// Draw draws the parts of the scene that have changed since it was
+// last drawn onto the same image, and returns whether anything was
+// drawn. The first time it is drawn, the whole context is cleared to
+// the background colour. Backends that don't fill when cleared, such
+// as draw2dsvg, need the background adding separately.
+func (scene *Scene) Draw(gc draw2d.GraphicContext) bool {
+	background := scene.Background()
+	cells := scene.Visible()
+
+	// work out which cells need drawing
+	redraw := make([]bool, len(cells))
+	cleared := []area{}
+	full := !scene.painted || background != scene.background
+	if full {
+		for i := range redraw {
+			redraw[i] = true
+		}
+	} else {
+		// anything drawn by cells that have since been removed is
+		// cleared
+		present := map[*letters.Cell]bool{}
+		for _, cell := range cells {
+			present[cell] = true
+		}
+		for cell, bounds := range scene.drawn {
+			if !present[cell] {
+				cleared = append(cleared, bounds)
+			}
+		}
+		for i, cell := range cells {
+			if !cell.Dirty() {
+				continue
+			}
+			redraw[i] = true
+			topLeft, bottomRight := cell.Bounds()
+			cleared = append(cleared, scene.area(topLeft, bottomRight))
+			if topLeft, bottomRight, drawn := cell.LastBounds(); drawn {
+				cleared = append(cleared, scene.area(topLeft, bottomRight))
+			}
+		}
+	}
+	if !full && len(cleared) == 0 {
+		return false
+	}
+	// cells overlapping a cleared area have to be drawn again in
+	// full, which clears the rest of their area too
+	for changed := true; changed; {
+		changed = false
+		for i, cell := range cells {
+			if redraw[i] {
+				continue
+			}
+			topLeft, bottomRight, _ := cell.LastBounds()
+			bounds := scene.area(topLeft, bottomRight)
+			for _, a := range cleared {
+				if bounds.intersects(a) {
+					redraw[i] = true
+					cleared = append(cleared, bounds)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	if full {
+		gc.SetFillColor(background)
+		gc.Clear()
+	}
+	for _, a := range cleared {
+		fillArea(gc, a, background)
+	}
+	scene.drawn = map[*letters.Cell]area{}
+	for i, cell := range cells {
+		if redraw[i] {
+			if i < len(cells)-len(scene.leaving) {
+				cell.DrawBackground(gc)
+			}
+			cell.Draw(gc)
+		}
+		topLeft, bottomRight, _ := cell.LastBounds()
+		scene.drawn[cell] = scene.area(topLeft, bottomRight)
+	}
+
+	remaining := scene.leaving[:0]
+	for _, cell := range scene.leaving {
+		if !cell.Faded() {
+			remaining = append(remaining, cell)
+		}
+	}
+	scene.leaving = remaining
+	scene.painted = true
+	scene.background = background
+	return true
+}
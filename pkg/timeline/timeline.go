@@ -0,0 +1,153 @@
+package timeline
+
+import (
+	"sort"
+	"time"
+
+	"github.com/llgcode/draw2d"
+)
+
+// Action is something done to the cells of a scene at a keyframe
+type Action interface {
+	// Start is called when the keyframe is reached, and returns the
+	// number of cells the action applies to
+	Start(scene *Scene) int
+	// Apply applies the action to the cell at the given index.
+	// progress goes from 0 to 1 over the action's duration, and
+	// Apply is called each frame until it reaches 1.
+	Apply(scene *Scene, index int, progress float64)
+	// Duration returns how long the action takes for each cell. An
+	// action with no duration is applied once, with a progress of 1.
+	Duration() time.Duration
+}
+
+// Keyframe schedules an action. The action starts on the first cell
+// At after the start of the timeline, and on each following cell
+// Stagger later than the one before it. A negative Stagger starts
+// from the last cell instead.
+type Keyframe struct {
+	At      time.Duration
+	Stagger time.Duration
+	Action  Action
+}
+
+// cellStart returns when the action starts on the cell at the given
+// index, out of count cells
+func (kf *Keyframe) cellStart(index, count int) time.Duration {
+	if kf.Stagger < 0 {
+		return kf.At - time.Duration(count-1-index)*kf.Stagger
+	}
+	return kf.At + time.Duration(index)*kf.Stagger
+}
+
+// runningKeyframe holds the progress of a keyframe that has been
+// reached
+type runningKeyframe struct {
+	Keyframe
+	count int
+	done  []bool
+}
+
+// Timeline plays keyframes on a scene
+type Timeline struct {
+	Scene *Scene
+
+	keyframes []Keyframe
+	next      int
+	running   []*runningKeyframe
+	start     time.Time
+}
+
+// New creates a Timeline for a scene
+func New(scene *Scene) *Timeline {
+	return &Timeline{
+		Scene: scene,
+	}
+}
+
+// Add schedules keyframes. Keyframes that are already due are played
+// the next time the timeline is updated.
+func (tl *Timeline) Add(keyframes ...Keyframe) {
+	pending := append(tl.keyframes[tl.next:len(tl.keyframes):len(tl.keyframes)], keyframes...)
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].At < pending[j].At
+	})
+	tl.keyframes = append(tl.keyframes[:tl.next], pending...)
+}
+
+// Elapsed returns how long the timeline has been playing for
+func (tl *Timeline) Elapsed() time.Duration {
+	if tl.start.IsZero() {
+		return 0
+	}
+	return tl.Scene.now().Sub(tl.start)
+}
+
+// Restart plays all of the keyframes again from the beginning, on
+// the timeline's current scene
+func (tl *Timeline) Restart() {
+	tl.start = time.Time{}
+	tl.next = 0
+	tl.running = nil
+}
+
+// Clear removes all of the keyframes and starts the timeline again
+func (tl *Timeline) Clear() {
+	tl.keyframes = nil
+	tl.Restart()
+}
+
+// Done returns whether all of the keyframes have finished
+func (tl *Timeline) Done() bool {
+	return tl.next == len(tl.keyframes) && len(tl.running) == 0
+}
+
+// update plays the keyframes that are due at the given time
+func (tl *Timeline) update(now time.Time) {
+	if tl.start.IsZero() {
+		tl.start = now
+	}
+	elapsed := now.Sub(tl.start)
+
+	for tl.next < len(tl.keyframes) && tl.keyframes[tl.next].At <= elapsed {
+		kf := &runningKeyframe{Keyframe: tl.keyframes[tl.next]}
+		kf.count = kf.Action.Start(tl.Scene)
+		kf.done = make([]bool, kf.count)
+		tl.running = append(tl.running, kf)
+		tl.next++
+	}
+
+	running := tl.running[:0]
+	for _, kf := range tl.running {
+		finished := true
+		for index := 0; index < kf.count; index++ {
+			if kf.done[index] {
+				continue
+			}
+			start := kf.cellStart(index, kf.count)
+			if elapsed < start {
+				finished = false
+				continue
+			}
+			progress := 1.0
+			if duration := kf.Action.Duration(); duration > 0 && elapsed-start < duration {
+				progress = float64(elapsed-start) / float64(duration)
+				finished = false
+			} else {
+				kf.done[index] = true
+			}
+			kf.Action.Apply(tl.Scene, index, progress)
+		}
+		if !finished {
+			running = append(running, kf)
+		}
+	}
+	tl.running = running
+}
+
+// Draw plays the keyframes that are due and draws the parts of the
+// scene that have changed. It returns whether anything was drawn.
+func (tl *Timeline) Draw(gc draw2d.GraphicContext) bool {
+	tl.update(tl.Scene.now())
+	return tl.Scene.Draw(gc)
+}
@@ -0,0 +1,462 @@
+package timeline
+
+import (
+	"image"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshbarrass/SnakeIsDead/pkg/letters"
+	"github.com/llgcode/draw2d/draw2dimg"
+)
+
+// epoch is the time the test clocks start at
+var epoch = time.Unix(0, 0)
+
+// newTestScene creates a scene with a manual clock, and a timeline
+// playing it
+func newTestScene() (*Scene, *Timeline, *letters.ManualClock) {
+	clock := letters.NewManualClock(epoch)
+	scene := NewScene([2]float64{0, 0})
+	scene.Clock = clock
+	return scene, New(scene), clock
+}
+
+// mustPhrase creates a Phrase action, failing the test if it can't
+func mustPhrase(t *testing.T, text string, font *letters.Font) *Phrase {
+	t.Helper()
+	phrase, _, err := NewPhrase(text, font, letters.FallbackError)
+	if err != nil {
+		t.Fatalf("NewPhrase(%q) returned error: %s", text, err)
+	}
+	return phrase
+}
+
+// present returns whether each cell of the phrase has been created
+func present(scene *Scene) []bool {
+	cells := make([]bool, len(scene.Cells))
+	for i, cell := range scene.Cells {
+		cells[i] = cell != nil
+	}
+	return cells
+}
+
+// recordAction is an action that records when it is started and
+// applied
+type recordAction struct {
+	name  string
+	count int
+	over  time.Duration
+	log   *[]string
+	// applied holds the progress of each call to Apply, by cell
+	applied [][]float64
+}
+
+func (a *recordAction) Start(scene *Scene) int {
+	*a.log = append(*a.log, a.name)
+	a.applied = make([][]float64, a.count)
+	return a.count
+}
+
+func (a *recordAction) Apply(scene *Scene, index int, progress float64) {
+	a.applied[index] = append(a.applied[index], progress)
+}
+
+func (a *recordAction) Duration() time.Duration {
+	return a.over
+}
+
+func TestKeyframeCellStart(t *testing.T) {
+	tests := []struct {
+		at, stagger  time.Duration
+		index, count int
+		want         time.Duration
+	}{
+		{0, 0, 2, 3, 0},
+		{100, 0, 2, 3, 100},
+		{100, 50, 0, 3, 100},
+		{100, 50, 2, 3, 200},
+		// a negative stagger starts from the last cell
+		{100, -50, 2, 3, 100},
+		{100, -50, 1, 3, 150},
+		{100, -50, 0, 3, 200},
+	}
+	for _, test := range tests {
+		kf := Keyframe{At: test.at, Stagger: test.stagger}
+		if got := kf.cellStart(test.index, test.count); got != test.want {
+			t.Errorf("cellStart(%d, %d) with At %v and Stagger %v = %v, want %v", test.index, test.count, test.at, test.stagger, got, test.want)
+		}
+	}
+}
+
+func TestTimelineAddOrder(t *testing.T) {
+	_, tl, clock := newTestScene()
+	log := []string{}
+	action := func(name string) *recordAction {
+		return &recordAction{name: name, count: 1, log: &log}
+	}
+
+	// keyframes are started in time order, and those at the same
+	// time in the order they were added
+	tl.Add(
+		Keyframe{At: 200 * time.Millisecond, Action: action("c")},
+		Keyframe{At: 0, Action: action("a")},
+		Keyframe{At: 200 * time.Millisecond, Action: action("d")},
+	)
+	tl.Add(Keyframe{At: 100 * time.Millisecond, Action: action("b")})
+	tl.update(clock.Now())
+	if want := []string{"a"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("started %v at 0ms, want %v", log, want)
+	}
+
+	// keyframes added once the timeline is playing are slotted in
+	// among those still to come, and ones already due are played
+	// straight away
+	clock.Advance(150 * time.Millisecond)
+	tl.Add(
+		Keyframe{At: 50 * time.Millisecond, Action: action("late")},
+		Keyframe{At: 300 * time.Millisecond, Action: action("e")},
+	)
+	tl.update(clock.Now())
+	if want := []string{"a", "late", "b"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("started %v at 150ms, want %v", log, want)
+	}
+
+	clock.Advance(200 * time.Millisecond)
+	tl.update(clock.Now())
+	if want := []string{"a", "late", "b", "c", "d", "e"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("started %v at 350ms, want %v", log, want)
+	}
+	if !tl.Done() {
+		t.Errorf("timeline not done after every keyframe has been played")
+	}
+}
+
+func TestTimelineOverlappingKeyframes(t *testing.T) {
+	_, tl, clock := newTestScene()
+	log := []string{}
+	first := &recordAction{name: "first", count: 2, over: 100 * time.Millisecond, log: &log}
+	second := &recordAction{name: "second", count: 1, over: 100 * time.Millisecond, log: &log}
+	tl.Add(
+		Keyframe{At: 0, Stagger: 50 * time.Millisecond, Action: first},
+		Keyframe{At: 50 * time.Millisecond, Action: second},
+	)
+	for i := 0; i < 5; i++ {
+		tl.update(clock.Now())
+		clock.Advance(50 * time.Millisecond)
+	}
+
+	// both keyframes run at the same time, and each cell finishes
+	// with a progress of 1
+	tests := []struct {
+		name string
+		got  [][]float64
+		want [][]float64
+	}{
+		{"first", first.applied, [][]float64{{0, 0.5, 1}, {0, 0.5, 1}}},
+		{"second", second.applied, [][]float64{{0, 0.5, 1}}},
+	}
+	for _, test := range tests {
+		if !reflect.DeepEqual(test.got, test.want) {
+			t.Errorf("%s keyframe applied with progress %v, want %v", test.name, test.got, test.want)
+		}
+	}
+	if !tl.Done() {
+		t.Errorf("timeline not done after every keyframe has finished")
+	}
+}
+
+func TestPhraseStagger(t *testing.T) {
+	tests := []struct {
+		stagger time.Duration
+		// want holds which cells exist at each step of 100ms
+		want [][]bool
+	}{
+		{0, [][]bool{{true, true, true}}},
+		{100 * time.Millisecond, [][]bool{
+			{true, false, false},
+			{true, true, false},
+			{true, true, true},
+		}},
+		{-100 * time.Millisecond, [][]bool{
+			{false, false, true},
+			{false, true, true},
+			{true, true, true},
+		}},
+	}
+	for _, test := range tests {
+		scene, tl, clock := newTestScene()
+		tl.Add(Keyframe{Stagger: test.stagger, Action: mustPhrase(t, "ABC", nil)})
+		for step, want := range test.want {
+			tl.update(clock.Now())
+			if got := present(scene); !reflect.DeepEqual(got, want) {
+				t.Errorf("stagger %v: cells at %v are %v, want %v", test.stagger, clock.Now().Sub(epoch), got, want)
+			}
+			if done := step == len(test.want)-1; tl.Done() != done {
+				t.Errorf("stagger %v: Done() at %v = %v, want %v", test.stagger, clock.Now().Sub(epoch), tl.Done(), done)
+			}
+			clock.Advance(100 * time.Millisecond)
+		}
+	}
+}
+
+func TestPhraseLength(t *testing.T) {
+	tests := []struct {
+		from, to string
+		// cells and visible are the number of cells in the phrase,
+		// and the number drawn including those fading out, once the
+		// new phrase has been applied to every cell
+		cells, visible int
+	}{
+		{"AB", "ABCD", 4, 4},
+		{"ABCD", "AB", 2, 4},
+		{"ABCD", "", 0, 4},
+		{"ABC", "XYZ", 3, 3},
+	}
+	for _, test := range tests {
+		scene, tl, clock := newTestScene()
+		tl.Add(
+			Keyframe{Action: mustPhrase(t, test.from, nil)},
+			Keyframe{At: time.Second, Stagger: 100 * time.Millisecond, Action: mustPhrase(t, test.to, nil)},
+		)
+		tl.update(clock.Now())
+
+		// the cells past the end of the new phrase are kept until
+		// every cell has changed, so the phrase doesn't move
+		clock.Advance(time.Second)
+		tl.update(clock.Now())
+		if want := len(test.from); len(test.to) < want && len(scene.Cells) != want {
+			t.Errorf("%q to %q: %d cells part way through, want %d", test.from, test.to, len(scene.Cells), want)
+		}
+
+		clock.Advance(time.Second)
+		tl.update(clock.Now())
+		if len(scene.Cells) != test.cells {
+			t.Errorf("%q to %q: %d cells, want %d", test.from, test.to, len(scene.Cells), test.cells)
+		}
+		if got := len(scene.Visible()); got != test.visible {
+			t.Errorf("%q to %q: %d visible cells, want %d", test.from, test.to, got, test.visible)
+		}
+		for i, cell := range scene.Cells {
+			if cell == nil {
+				t.Errorf("%q to %q: cell %d is missing", test.from, test.to, i)
+			}
+		}
+	}
+}
+
+func TestLayoutCellStyle(t *testing.T) {
+	scene, tl, clock := newTestScene()
+	tl.Add(Keyframe{Action: mustPhrase(t, "A.B", nil)})
+	tl.update(clock.Now())
+
+	// the full stop's cell is given its own, thinner style, which the
+	// next phrase is spaced with as it keeps the cell
+	style := letters.DefaultStyle.WithWeight(10)
+	scene.Cells[1].Style = style
+	phrase := []letters.Letter{letters.LetterA(), letters.LetterPeriod(), letters.LetterB()}
+	positions := scene.Layout(phrase, letters.DefaultFont)
+	want := 2*scene.LetterSpacing - letters.DefaultWidth + style.StickThickness
+	if positions[2][0] != want {
+		t.Errorf("letter after the full stop is at %v, want %v", positions[2][0], want)
+	}
+}
+
+func TestPhraseFontChange(t *testing.T) {
+	tests := []struct {
+		name    string
+		font    *letters.Font
+		replace bool
+	}{
+		{"same font", letters.DefaultFont, false},
+		{"new font", letters.HeavyFont, true},
+	}
+	for _, test := range tests {
+		scene, tl, clock := newTestScene()
+		tl.Add(Keyframe{Action: mustPhrase(t, "AB", letters.DefaultFont)})
+		tl.update(clock.Now())
+		before := append([]*letters.Cell{}, scene.Cells...)
+
+		clock.Advance(time.Second)
+		tl.Add(Keyframe{At: time.Second, Action: mustPhrase(t, "AB", test.font)})
+		tl.update(clock.Now())
+
+		for i, cell := range scene.Cells {
+			if replaced := cell != before[i]; replaced != test.replace {
+				t.Errorf("%s: cell %d replaced = %v, want %v", test.name, i, replaced, test.replace)
+			}
+			if cell.Font != test.font {
+				t.Errorf("%s: cell %d has font %s, want %s", test.name, i, cell.Font.Name, test.font.Name)
+			}
+			if got, want := cell.Width(), test.font.Width; got != want {
+				t.Errorf("%s: cell %d is %v wide, want %v", test.name, i, got, want)
+			}
+		}
+		// replaced cells fade out rather than disappearing
+		want := len(scene.Cells)
+		if test.replace {
+			want += len(before)
+		}
+		if got := len(scene.Visible()); got != want {
+			t.Errorf("%s: %d visible cells, want %d", test.name, got, want)
+		}
+	}
+}
+
+func TestCellActions(t *testing.T) {
+	const over = 100 * time.Millisecond
+	tests := []struct {
+		name   string
+		action Action
+		// want holds the top left and bottom right of the first
+		// cell at the start, half way through and at the end of the
+		// action
+		want [3][2][2]float64
+	}{
+		{"translate", &Translate{DX: 10, DY: -20, Over: over}, [3][2][2]float64{
+			{{0, 0}, {51, 85}},
+			{{5, -10}, {56, 75}},
+			{{10, -20}, {61, 65}},
+		}},
+		{"scale", &Scale{Factor: 4, Over: over}, [3][2][2]float64{
+			{{0, 0}, {51, 85}},
+			{{-25.5, -42.5}, {76.5, 127.5}},
+			{{-76.5, -127.5}, {127.5, 212.5}},
+		}},
+		{"instant translate", &Translate{DX: 10, DY: -20}, [3][2][2]float64{
+			{{10, -20}, {61, 65}},
+			{{10, -20}, {61, 65}},
+			{{10, -20}, {61, 65}},
+		}},
+	}
+	for _, test := range tests {
+		scene, tl, clock := newTestScene()
+		tl.Add(
+			Keyframe{Action: mustPhrase(t, "AB", nil)},
+			Keyframe{At: time.Second, Action: test.action},
+		)
+		tl.update(clock.Now())
+		clock.Advance(time.Second)
+		for step, want := range test.want {
+			tl.update(clock.Now())
+			for i, cell := range scene.Cells {
+				// the second cell starts a letter spacing along
+				offset := float64(i * DefaultLetterSpacing)
+				got := [2][2]float64{
+					{cell.TopLeft[0] - offset, cell.TopLeft[1]},
+					{cell.BottomRight[0] - offset, cell.BottomRight[1]},
+				}
+				if !closeCorners(got, want) {
+					t.Errorf("%s: cell %d at step %d is at %v, want %v", test.name, i, step, got, want)
+				}
+			}
+			clock.Advance(over / 2)
+		}
+	}
+}
+
+// closeCorners returns whether two sets of corners are within
+// rounding error of each other
+func closeCorners(a, b [2][2]float64) bool {
+	for i := range a {
+		for j := range a[i] {
+			if d := a[i][j] - b[i][j]; d > 1e-9 || d < -1e-9 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestFadeSegments(t *testing.T) {
+	scene, tl, clock := newTestScene()
+	tl.Add(
+		Keyframe{Action: mustPhrase(t, "E", nil)},
+		Keyframe{At: 2 * time.Second, Action: &FadeSegments{IDs: []letters.SegmentID{letters.IDEMiddle}}},
+	)
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	gc := draw2dimg.NewGraphicContext(img)
+	// the letter is drawn until it has faded in, and then the
+	// segment starts fading out
+	for i := 0; i < 2; i++ {
+		tl.Draw(gc)
+		clock.Advance(time.Second)
+	}
+	tl.Draw(gc)
+	clock.Advance(letters.DefaultLifecycle.FadeOut / 2)
+	tl.Draw(gc)
+
+	// the segment is only removed once it has finished fading out
+	ids := func() []letters.SegmentID {
+		ids := []letters.SegmentID{}
+		for _, seg := range scene.Cells[0].Segments {
+			ids = append(ids, seg.ID())
+		}
+		return ids
+	}
+	if want := []letters.SegmentID{letters.IDNBar, letters.IDELower, letters.IDEMiddle}; !reflect.DeepEqual(ids(), want) {
+		t.Errorf("segments while fading are %v, want %v", ids(), want)
+	}
+	clock.Advance(letters.DefaultLifecycle.FadeOut / 2)
+	tl.Draw(gc)
+	if want := []letters.SegmentID{letters.IDNBar, letters.IDELower}; !reflect.DeepEqual(ids(), want) {
+		t.Errorf("segments after fading are %v, want %v", ids(), want)
+	}
+}
+
+func TestParseKeyframes(t *testing.T) {
+	const file = `[
+		{"at": 0, "stagger": 100, "action": "phrase", "text": "SNAKE IS DEAD"},
+		{"at": 2000, "action": "phrase", "text": "PARADOX", "font": "heavy", "policy": "tofu", "morph": 500},
+		{"at": 3000, "stagger": -50, "action": "mode", "mode": "paradox", "fade": 1000},
+		{"at": 4000, "action": "translate", "dx": 0, "dy": 20, "over": 500},
+		{"at": 4000, "action": "scale", "factor": 1.5, "over": 500},
+		{"at": 5000.5, "action": "fade-segments", "ids": [3, 4]}
+	]`
+	keyframes, err := ParseKeyframes(strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("ParseKeyframes returned error: %s", err)
+	}
+	want := []Keyframe{
+		{At: 0, Stagger: 100 * time.Millisecond, Action: &Phrase{Text: "SNAKE IS DEAD", Font: letters.DefaultFont, Policy: letters.FallbackError}},
+		{At: 2 * time.Second, Action: &Phrase{Text: "PARADOX", Font: letters.HeavyFont, Policy: letters.FallbackTofu, Morph: true, MorphDuration: 500 * time.Millisecond}},
+		{At: 3 * time.Second, Stagger: -50 * time.Millisecond, Action: &Mode{Mode: letters.ModeParadox, Fade: time.Second}},
+		{At: 4 * time.Second, Action: &Translate{DY: 20, Over: 500 * time.Millisecond}},
+		{At: 4 * time.Second, Action: &Scale{Factor: 1.5, Over: 500 * time.Millisecond}},
+		{At: 5000500 * time.Microsecond, Action: &FadeSegments{IDs: []letters.SegmentID{3, 4}}},
+	}
+	if !reflect.DeepEqual(keyframes, want) {
+		t.Errorf("ParseKeyframes returned %+v, want %+v", keyframes, want)
+	}
+}
+
+func TestParseKeyframesErrors(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{`{"at": 0}`, "could not parse keyframe file"},
+		{`[{"at": "soon", "action": "mode"}]`, "could not parse keyframe file"},
+		{`[{"at": 0, "action": "translate", "over": "1s"}]`, "could not parse keyframe file"},
+		{`[{"at": 0, "action": "explode"}]`, "keyframe 0: unknown action 'explode'"},
+		{`[{"at": 0, "stager": 100, "action": "phrase", "text": "A"}]`, `unknown field "stager"`},
+		{`[{"at": 0, "action": "phrase", "text": "A", "fallbak": "tofu"}]`, `unknown field "fallbak"`},
+		{`[{"at": 0, "action": "mode", "mode": "paradox"}, {"action": "mode", "mode": "sepia"}]`, "keyframe 1:"},
+		{`[{"at": 0, "action": "phrase", "text": "A", "font": "comic"}]`, "font 'comic'"},
+		{`[{"at": 0, "action": "phrase", "text": "A", "policy": "guess"}]`, "keyframe 0:"},
+		{`[{"at": 0, "action": "phrase", "text": "☃"}]`, "keyframe 0:"},
+		{`[{"at": 0, "action": "scale"}]`, "scale needs a positive factor"},
+		{`[{"at": 0, "action": "scale", "factor": 0}]`, "scale needs a positive factor"},
+	}
+	for _, test := range tests {
+		_, err := ParseKeyframes(strings.NewReader(test.file))
+		if err == nil {
+			t.Errorf("ParseKeyframes(%s) returned no error, want %q", test.file, test.want)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.want) {
+			t.Errorf("ParseKeyframes(%s) returned error %q, want %q", test.file, err, test.want)
+		}
+	}
+}
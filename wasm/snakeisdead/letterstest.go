@@ -1,77 +1,86 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"log"
 	"strings"
 	"syscall/js"
+	"time"
 
 	"github.com/joshbarrass/SnakeIsDead/pkg/letters"
+	"github.com/joshbarrass/SnakeIsDead/pkg/render"
+	"github.com/joshbarrass/SnakeIsDead/pkg/timeline"
 	"github.com/llgcode/draw2d/draw2dimg"
 	"github.com/markfarnan/go-canvas/canvas"
 )
 
-// LetterSpacing is the space in pixels between the left edge of
-// letters
-const LetterSpacing = 100
-
-func getCells(text string, topLeft [2]float64) ([]*letters.Cell, error) {
-	letterMap := letters.GetLetterMap()
-	cells := []*letters.Cell{}
-	for i, char := range text {
-		letterFunc, ok := letterMap[byte(char)]
-		if !ok {
-			return nil, fmt.Errorf("character '%s' not available", string(char))
+// substitutionsToJS converts substitutions into values that can be
+// returned to JS
+func substitutionsToJS(substitutions []letters.Substitution) []interface{} {
+	values := make([]interface{}, len(substitutions))
+	for i, sub := range substitutions {
+		values[i] = map[string]interface{}{
+			"index":       sub.Index,
+			"original":    string(sub.Original),
+			"replacement": string(sub.Replacement),
 		}
-		cells = append(cells, letters.NewCell(
-			[2]float64{topLeft[0] + float64(LetterSpacing*i), topLeft[1]},
-			[2]float64{topLeft[0] + float64(LetterSpacing*i) + letters.DefaultWidth, topLeft[1] + letters.DefaultHeight},
-			letterFunc(),
-			letters.ColorsDeath,
-			letters.ColorsParadox,
-		))
 	}
-	return cells, nil
+	return values
 }
 
-// getDrawingFunction returns a function for drawing the cells for a
-// phrase
-func getDrawingFunction(phrase string) (func(*draw2dimg.GraphicContext) bool, error) {
-	cells, err := getCells(phrase, [2]float64{20, 20})
-	if err != nil {
-		return nil, err
-	}
-	return func(gc *draw2dimg.GraphicContext) bool {
-		// fill background
-		gc.SetFillColor(cells[0].DeathColors[0])
-		gc.MoveTo(0, 0)
-		gc.LineTo(0, 10000)
-		gc.LineTo(10000, 10000)
-		gc.LineTo(10000, 0)
-		gc.LineTo(0, 0)
-		gc.Fill()
-		// draw cell
-		for _, cell := range cells {
-			cell.Draw(gc)
-		}
-		return true
-	}, nil
-}
-
-// Updater is a struct responsible for storing the drawing function
-// and wrapping it, allowing it to be updated more easily
+// Updater is a struct responsible for storing the timeline and
+// wrapping it, allowing it to be replaced more easily
 type Updater struct {
-	Func func(*draw2dimg.GraphicContext) bool
+	Timeline *timeline.Timeline
 }
 
-// Draw wraps the drawing function
+// Draw plays the timeline and draws the parts of its scene that have
+// changed. The canvas is only updated if something was drawn, so it
+// idles while nothing is animating.
 func (updater *Updater) Draw(gc *draw2dimg.GraphicContext) bool {
-	return updater.Func(gc)
+	return updater.Timeline.Draw(gc)
+}
+
+// Now schedules an action to happen straight away
+func (updater *Updater) Now(action timeline.Action) {
+	updater.Timeline.Add(timeline.Keyframe{
+		At:     updater.Timeline.Elapsed(),
+		Action: action,
+	})
 }
 
-// SetFunc updates the drawing function
-func (updater *Updater) SetFunc(f func(*draw2dimg.GraphicContext) bool) {
-	updater.Func = f
+// AddKeyframes schedules keyframes, with times relative to now
+func (updater *Updater) AddKeyframes(keyframes []timeline.Keyframe) {
+	elapsed := updater.Timeline.Elapsed()
+	for i := range keyframes {
+		keyframes[i].At += elapsed
+	}
+	updater.Timeline.Add(keyframes...)
+}
+
+// newTimeline creates a timeline for an empty scene
+func newTimeline() *timeline.Timeline {
+	return timeline.New(timeline.NewScene([2]float64{20, 20}))
+}
+
+// download offers data to the user as a file
+func download(filename, mimeType string, data []byte) {
+	array := js.Global().Get("Uint8Array").New(len(data))
+	js.CopyBytesToJS(array, data)
+	blob := js.Global().Get("Blob").New([]interface{}{array}, map[string]interface{}{
+		"type": mimeType,
+	})
+	url := js.Global().Get("URL").Call("createObjectURL", blob)
+	defer js.Global().Get("URL").Call("revokeObjectURL", url)
+
+	link := js.Global().Get("document").Call("createElement", "a")
+	link.Set("href", url)
+	link.Set("download", filename)
+	js.Global().Get("document").Get("body").Call("appendChild", link)
+	link.Call("click")
+	js.Global().Get("document").Get("body").Call("removeChild", link)
 }
 
 func main() {
@@ -84,33 +93,207 @@ func main() {
 		log.Fatalf("Could not create canvas")
 	}
 
-	drawFunc, err := getDrawingFunction("SNAKE IS DEAD")
+	policy := letters.FallbackError
+	// the phrase currently shown, for downloading
+	currentText, currentFont := "SNAKE IS DEAD", letters.DefaultFont
+	phrase, _, err := timeline.NewPhrase(currentText, currentFont, policy)
 	if err != nil {
-		panic(fmt.Sprintf("failed to get drawing function: %s", err))
+		panic(fmt.Sprintf("failed to create phrase: %s", err))
 	}
 	updater := Updater{
-		Func: drawFunc,
+		Timeline: newTimeline(),
 	}
+	updater.Now(phrase)
 
 	js.Global().Set("UpdatePhrase", js.FuncOf(
 		func(this js.Value, i []js.Value) interface{} {
-			if len(i) != 1 {
+			if len(i) < 1 || len(i) > 2 {
 				return map[string]interface{}{
 					"error": "wrong number of arguments",
 				}
 			}
 			phrase := i[0].String()
-			newFunc, err := getDrawingFunction(strings.ToUpper(phrase))
+			font := letters.DefaultFont
+			if len(i) == 2 {
+				var ok bool
+				font, ok = letters.LookupFont(i[1].String())
+				if !ok {
+					return map[string]interface{}{
+						"error": fmt.Sprintf("font '%s' not available", i[1].String()),
+					}
+				}
+			}
+			action, substitutions, err := timeline.NewPhrase(strings.ToUpper(phrase), font, policy)
+			if err != nil {
+				return map[string]interface{}{
+					"error": err.Error(),
+				}
+			}
+			updater.Now(action)
+			currentText, currentFont = strings.ToUpper(phrase), font
+			return map[string]interface{}{
+				"substitutions": substitutionsToJS(substitutions),
+			}
+		},
+	))
+
+	js.Global().Set("SetFallbackPolicy", js.FuncOf(
+		func(this js.Value, i []js.Value) interface{} {
+			if len(i) != 1 {
+				return map[string]interface{}{
+					"error": "wrong number of arguments",
+				}
+			}
+			newPolicy, err := letters.ParseFallbackPolicy(i[0].String())
 			if err != nil {
 				return map[string]interface{}{
 					"error": err.Error(),
 				}
 			}
-			updater.Func = newFunc
+			policy = newPolicy
 			return map[string]interface{}{}
 		},
 	))
 
+	js.Global().Set("SetMode", js.FuncOf(
+		func(this js.Value, i []js.Value) interface{} {
+			if len(i) < 1 || len(i) > 3 {
+				return map[string]interface{}{
+					"error": "wrong number of arguments",
+				}
+			}
+			newMode, err := letters.ParseColorMode(i[0].String())
+			if err != nil {
+				return map[string]interface{}{
+					"error": err.Error(),
+				}
+			}
+			var duration time.Duration
+			if len(i) >= 2 {
+				duration = time.Duration(i[1].Float() * float64(time.Millisecond))
+			}
+			// a cell index changes only that cell, otherwise the
+			// whole phrase changes
+			if len(i) == 3 {
+				cells := updater.Timeline.Scene.Cells
+				index := i[2].Int()
+				if index < 0 || index >= len(cells) || cells[index] == nil {
+					return map[string]interface{}{
+						"error": fmt.Sprintf("cell %d out of range", index),
+					}
+				}
+				cells[index].SetMode(newMode, duration)
+				return map[string]interface{}{}
+			}
+			updater.Now(&timeline.Mode{Mode: newMode, Fade: duration})
+			return map[string]interface{}{}
+		},
+	))
+
+	js.Global().Set("AddKeyframes", js.FuncOf(
+		func(this js.Value, i []js.Value) interface{} {
+			if len(i) != 1 {
+				return map[string]interface{}{
+					"error": "wrong number of arguments",
+				}
+			}
+			keyframes, err := timeline.ParseKeyframes(strings.NewReader(i[0].String()))
+			if err != nil {
+				return map[string]interface{}{
+					"error": err.Error(),
+				}
+			}
+			updater.AddKeyframes(keyframes)
+			return map[string]interface{}{}
+		},
+	))
+
+	js.Global().Set("PlayTimeline", js.FuncOf(
+		func(this js.Value, i []js.Value) interface{} {
+			if len(i) != 1 {
+				return map[string]interface{}{
+					"error": "wrong number of arguments",
+				}
+			}
+			keyframes, err := timeline.ParseKeyframes(strings.NewReader(i[0].String()))
+			if err != nil {
+				return map[string]interface{}{
+					"error": err.Error(),
+				}
+			}
+			// start again from an empty scene
+			tl := newTimeline()
+			tl.Add(keyframes...)
+			updater.Timeline = tl
+			return map[string]interface{}{}
+		},
+	))
+
+	js.Global().Set("LoadGlyphs", js.FuncOf(
+		func(this js.Value, i []js.Value) interface{} {
+			if len(i) < 1 || len(i) > 2 {
+				return map[string]interface{}{
+					"error": "wrong number of arguments",
+				}
+			}
+			font := letters.DefaultFont
+			if len(i) == 2 {
+				var ok bool
+				font, ok = letters.LookupFont(i[1].String())
+				if !ok {
+					return map[string]interface{}{
+						"error": fmt.Sprintf("font '%s' not available", i[1].String()),
+					}
+				}
+			}
+			if err := font.LoadGlyphs(strings.NewReader(i[0].String())); err != nil {
+				return map[string]interface{}{
+					"error": err.Error(),
+				}
+			}
+			return map[string]interface{}{}
+		},
+	))
+
+	// downloadFunc creates a function that renders the keyframes given
+	// as JSON, or else the current phrase, and downloads the result
+	downloadFunc := func(filename, mimeType string, write func(w io.Writer, keyframes []timeline.Keyframe, opts render.Options) error) js.Func {
+		return js.FuncOf(
+			func(this js.Value, i []js.Value) interface{} {
+				if len(i) > 1 {
+					return map[string]interface{}{
+						"error": "wrong number of arguments",
+					}
+				}
+				var keyframes []timeline.Keyframe
+				var err error
+				if len(i) == 1 {
+					keyframes, err = timeline.ParseKeyframes(strings.NewReader(i[0].String()))
+				} else {
+					keyframes, err = render.PhraseKeyframes(currentText, currentFont, policy)
+				}
+				if err != nil {
+					return map[string]interface{}{
+						"error": err.Error(),
+					}
+				}
+				var buf bytes.Buffer
+				err = write(&buf, keyframes, render.Options{
+					Padding: 20,
+				})
+				if err != nil {
+					return map[string]interface{}{
+						"error": err.Error(),
+					}
+				}
+				download(filename, mimeType, buf.Bytes())
+				return map[string]interface{}{}
+			},
+		)
+	}
+	js.Global().Set("DownloadGIF", downloadFunc("snakeisdead.gif", "image/gif", render.WriteGIF))
+	js.Global().Set("DownloadSVG", downloadFunc("snakeisdead.svg", "image/svg+xml", render.WriteSVG))
+
 	cvs.Start(30, updater.Draw)
 
 	// channel is unused, so this prevents main from terminating and killing the WASM